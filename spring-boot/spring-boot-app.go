@@ -21,9 +21,13 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"os/signal"
 	"path"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
+	"syscall"
 
 	"github.com/go-spring/go-spring-parent/spring-logger"
 	"github.com/go-spring/go-spring/spring-core"
@@ -36,11 +40,19 @@ const (
 	SPRING_ACCESS  = "SPRING_ACCESS"
 	SpringProfile  = "spring.profile" // 运行环境
 	SPRING_PROFILE = "SPRING_PROFILE"
+
+	SpringConfigReloadEnabled    = "spring.config.reload.enabled" // 是否开启属性值的动态刷新
+	SPRING_CONFIG_RELOAD_ENABLED = "SPRING_CONFIG_RELOAD_ENABLED"
+
+	SpringProfilesInclude   = "spring.profiles.include" // 额外包含的运行环境
+	SPRING_PROFILES_INCLUDE = "SPRING_PROFILES_INCLUDE"
+
+	SpringProfilesGroup = "spring.profiles.group" // 运行环境分组，如 spring.profiles.group.prod=proddb,prodmq
 )
 
 var (
 	_ = flag.String(SpringAccess, "", "是否允许注入私有字段")
-	_ = flag.String(SpringProfile, "", "设置运行环境")
+	_ = flag.String(SpringProfile, "", "设置运行环境，多个运行环境使用逗号分隔")
 )
 
 // CommandLineRunner 命令行启动器接口
@@ -54,12 +66,31 @@ type ApplicationEvent interface {
 	OnStopApplication(ctx ApplicationContext)  // 应用停止的事件
 }
 
+// PropertyChangeEvent 属性值发生变化的事件
+type PropertyChangeEvent struct {
+	Keys []string // 发生变化的属性名，包括新增和删除的属性
+}
+
+// PropertyChangeListener 属性值变化的监听器
+type PropertyChangeListener interface {
+	OnPropertyChange(ctx ApplicationContext, event PropertyChangeEvent)
+}
+
 // application SpringBoot 应用
 type application struct {
-	appCtx      ApplicationContext  // 应用上下文
-	cfgLocation []string            // 配置文件目录
-	Events      []ApplicationEvent  `autowire:"${application-event.collection:=[]?}"`
-	Runners     []CommandLineRunner `autowire:"${command-line-runner.collection:=[]?}"`
+	appCtx      ApplicationContext       // 应用上下文
+	cfgLocation []string                 // 配置文件目录
+	modules     []*Module                // 自动配置模块
+	defaults    SpringCore.Properties    // 内部默认配置
+	cmdLine     *commandLine             // 解析后的命令行参数
+	apiConfig   SpringCore.Properties    // 通过代码设置的属性值
+	profiles    []string                 // 通过代码设置的运行环境
+	sources     []PropertySource         // 配置路径对应的属性源
+	stopWatch   []func()                 // 停止监听属性源
+	reloadMutex sync.Mutex               // 保证属性值重新加载串行执行
+	Events      []ApplicationEvent       `autowire:"${application-event.collection:=[]?}"`
+	Runners     []CommandLineRunner      `autowire:"${command-line-runner.collection:=[]?}"`
+	Listeners   []PropertyChangeListener `autowire:"${property-change-listener.collection:=[]?}"`
 }
 
 // newApplication application 的构造函数
@@ -70,6 +101,7 @@ func newApplication(appCtx ApplicationContext, cfgLocation ...string) *applicati
 	return &application{
 		appCtx:      appCtx,
 		cfgLocation: cfgLocation,
+		defaults:    SpringCore.NewDefaultProperties(),
 	}
 }
 
@@ -82,13 +114,29 @@ func (app *application) Start() {
 	// 准备上下文环境
 	app.prepare()
 
+	// 注册自动配置模块
+	app.applyModules()
+
 	// 注册 ApplicationContext
 	app.appCtx.RegisterBean(app)
 	app.appCtx.RegisterBean(app.appCtx)
 
+	// 执行 --help、--config-metadata 等命令然后退出
+	if app.cmdLine.command != "" {
+		metadata := collectPropertyMetadata(app.appCtx)
+		runCommand(os.Stdout, app.cmdLine, os.Args[0], metadata)
+		return
+	}
+
 	// 依赖注入、属性绑定、Bean 初始化
 	app.appCtx.AutoWireBeans()
 
+	// 打印配置属性 Bean 绑定的属性值
+	app.printConfigurationProperties()
+
+	// 严格模式下检查未使用的属性
+	app.checkUnusedProperties()
+
 	// 执行命令行启动器
 	for _, r := range app.Runners {
 		r.Run(app.appCtx)
@@ -107,6 +155,7 @@ func (app *application) printBanner() {
 	printDefaultBanner := true
 
 	for _, configLocation := range app.cfgLocation {
+		configLocation = strings.TrimPrefix(configLocation, "file:")
 		if stat, err := os.Stat(configLocation); err == nil && stat.IsDir() {
 			f := path.Join(configLocation, "banner.txt")
 			if stat, err = os.Stat(f); err == nil && !stat.IsDir() {
@@ -126,25 +175,16 @@ func (app *application) printBanner() {
 	}
 }
 
-// loadCmdArgs 加载命令行参数，以短线定义的参数才有效。
-func (_ *application) loadCmdArgs() SpringCore.Properties {
+// loadCmdArgs 加载命令行参数，参数的解析规则见 parseCommandLine。
+func (app *application) loadCmdArgs() SpringCore.Properties {
 	SpringLogger.Debugf("load cmd args")
-	p := SpringCore.NewDefaultProperties()
-	for i := 0; i < len(os.Args); i++ { // 以短线定义的参数才有效
-		if arg := os.Args[i]; strings.HasPrefix(arg, "-") {
-			k, v := arg[1:], ""
-			if i < len(os.Args)-1 && !strings.HasPrefix(os.Args[i+1], "-") {
-				v = os.Args[i+1]
-				i++
-			}
-			SpringLogger.Tracef("%s=%v", k, v)
-			p.SetProperty(k, v)
-		}
-	}
-	return p
+	return app.cmdLine.properties()
 }
 
-// loadSystemEnv 加载系统环境变量，用户可以自定义有效环境变量的正则匹配
+// loadSystemEnv 加载系统环境变量，用户可以自定义有效环境变量的正则匹配，
+// 环境变量的名称按照 SpringCore.EnvToPropertyKey 的规则转换为属性名。值为空的
+// 环境变量形式的名称，比如 SPRING_ACCESS=，通常表示没有设置，因此被忽略；直接
+// 使用属性名的环境变量，比如 spring.access=，仍然覆盖配置文件中的属性值。
 func (_ *application) loadSystemEnv() SpringCore.Properties {
 
 	var rex []*regexp.Regexp
@@ -161,10 +201,26 @@ func (_ *application) loadSystemEnv() SpringCore.Properties {
 	for _, env := range os.Environ() {
 		if i := strings.Index(env, "="); i > 0 {
 			k, v := env[0:i], env[i+1:]
+
+			// 秘钥只用于创建解密器，不作为属性值，避免出现在日志和诊断输出中
+			if k == SPRING_CONFIG_ENCRYPT_KEY {
+				continue
+			}
+
+			if v == "" && !strings.Contains(k, ".") {
+				continue
+			}
+
 			for _, r := range rex {
 				if r.MatchString(k) { // 符合匹配规则的才有效
-					SpringLogger.Tracef("%s=%v", k, v)
-					p.SetProperty(k, v)
+					origin := SpringCore.PropertyOrigin{Layer: "system-env", Source: k}
+					k = SpringCore.EnvToPropertyKey(k)
+					if isEncryptConfigKey(k) {
+						SpringLogger.Tracef("%s=%v", k, SpringCore.MaskedPropertyValue)
+					} else {
+						SpringLogger.Tracef("%s=%v", k, SpringCore.MaskPropertyValue(v))
+					}
+					p.SetPropertyWithOrigin(k, v, origin)
 					break
 				}
 			}
@@ -173,62 +229,113 @@ func (_ *application) loadSystemEnv() SpringCore.Properties {
 	return p
 }
 
-// loadProfileConfig 加载指定环境的配置文件
-func (app *application) loadProfileConfig(profile string) SpringCore.Properties {
-	p := SpringCore.NewDefaultProperties()
+// loadPropertySources 根据配置路径创建属性源，并按照优先级从低到高排序。
+func (app *application) loadPropertySources() []PropertySource {
+	var sources []PropertySource
 	for _, configLocation := range app.cfgLocation {
-		var result map[string]interface{}
-		if ss := strings.Split(configLocation, ":"); len(ss) == 1 {
-			result = NewDefaultPropertySource(ss[0]).Load(profile)
-		} else {
-			switch ss[0] {
-			case "k8s": // "k8s:testdata/config/config-map.yaml"
-				result = NewConfigMapPropertySource(ss[1]).Load(profile)
-			}
-		}
-		for k, v := range result {
-			SpringLogger.Tracef("%s=%v", k, v)
-			p.SetProperty(k, v)
+		sources = append(sources, NewPropertySource(configLocation))
+	}
+	sortPropertySources(sources)
+	return sources
+}
+
+// loadProfileConfig 从属性源中加载指定环境的配置文件，高优先级的属性源覆盖低优先级的
+// 属性源，列表作为一个整体被覆盖。active 是当前所有生效的运行环境。
+func (app *application) loadProfileConfig(sources []PropertySource, profile string, active []string) SpringCore.Properties {
+
+	layer := "app-config"
+	if profile != "" {
+		layer = "profile-config"
+	}
+
+	var merged SpringCore.Properties = SpringCore.NewDefaultProperties()
+	for _, source := range sources {
+		if result := app.loadSource(source, profile, active); result != nil {
+			merged = SpringCore.NewPriorityProperties(result, merged)
 		}
 	}
+
+	p := SpringCore.NewDefaultProperties()
+	copyProperties(merged, p, layer)
 	return p
 }
 
-// resolveProperty 解析属性值，查看其是否具有引用关系
-func resolveProperty(properties map[string]interface{}, key string, value interface{}) interface{} {
-	if s, ok := value.(string); ok && strings.HasPrefix(s, "${") {
-		refKey := s[2 : len(s)-1]
-		if refValue, ok := properties[refKey]; !ok {
-			panic(fmt.Errorf("properties \"%s\" not config", refKey))
-		} else {
-			refValue = resolveProperty(properties, refKey, refValue)
-			properties[key] = refValue
-			return refValue
+// loadSource 从属性源中加载属性值。执行 --help、--config-metadata 等命令时属性源
+// 加载失败只打印警告并忽略该属性源，这样配置中心等远程属性源不可用时仍然可以执行
+// 命令；正常启动时加载失败会 panic。
+func (app *application) loadSource(source PropertySource, profile string, active []string) (result SpringCore.Properties) {
+	if app.cmdLine != nil && app.cmdLine.command != "" {
+		defer func() {
+			if r := recover(); r != nil {
+				SpringLogger.Warnf("load property source %s error: %v", source.Name(), r)
+				result = nil
+			}
+		}()
+	}
+	return source.Load(profile, active)
+}
+
+// loadDefaultConfig 加载由 starter 和应用设置的内部默认配置
+func (app *application) loadDefaultConfig() SpringCore.Properties {
+	SpringLogger.Debugf("load default config")
+	p := SpringCore.NewDefaultProperties()
+	copyProperties(app.defaults, p, "default-config")
+	return p
+}
+
+// copyProperties 将 from 中的属性值及其来源拷贝到 to，并将来源的配置层设置为 layer。
+func copyProperties(from SpringCore.Properties, to SpringCore.Properties, layer string) {
+	masked := maskProperties(from)
+	for k, v := range from.GetProperties() {
+		origin, _ := from.GetPropertyOrigin(k)
+		origin.Layer = layer
+		SpringLogger.Tracef("%s=%v", k, masked[k])
+		to.SetPropertyWithOrigin(from.GetPropertyName(k), v, origin)
+	}
+}
+
+// expandProfileGroups 展开 spring.profiles.group.<name> 定义的运行环境分组，分组的
+// 成员紧跟在分组之后，成员也可以是分组，重复的运行环境只保留第一次出现的位置。
+func expandProfileGroups(p SpringCore.Properties, profiles []string) []string {
+	var result []string
+	seen := make(map[string]bool)
+
+	var expand func(profiles []string)
+	expand = func(profiles []string) {
+		for _, s := range profiles {
+			if seen[s] {
+				continue
+			}
+			seen[s] = true
+			result = append(result, s)
+			expand(propertyList(p.GetProperty(SpringProfilesGroup + "." + s)))
 		}
 	}
-	return value
+
+	expand(profiles)
+	return result
 }
 
-// prepare 准备上下文环境
-func (app *application) prepare() {
+// loadProperties 加载所有配置层的属性值，然后按照优先级进行重组，返回重组后的属性值
+// 以及所有生效的运行环境。
+func (app *application) loadProperties() (SpringCore.Properties, []string) {
 
 	// 配置项加载顺序优先级，从高到低:
 	// 1.代码设置
 	// 2.命令行参数
 	// 3.系统环境变量
-	// 4.application-profile.properties
+	// 4.application-profile.properties，按照运行环境的声明顺序，以及包含的运行环境的配置文件
 	// 5.application.properties
 	// 6.内部默认配置
 
-	// 将通过代码设置的属性值拷贝一份，第 1 层
-	apiConfig := SpringCore.NewDefaultProperties()
-	for k, v := range app.appCtx.GetProperties() {
-		apiConfig.SetProperty(k, v)
-	}
-
 	// 加载默认的应用配置文件，如 application.properties，第 5 层
-	appConfig := app.loadProfileConfig("")
-	p := SpringCore.NewPriorityProperties(apiConfig, appConfig)
+	appConfig := app.loadProfileConfig(app.sources, "", nil)
+
+	// 内部默认配置，第 6 层
+	defaults := app.loadDefaultConfig()
+
+	p := SpringCore.NewPriorityProperties(app.apiConfig,
+		SpringCore.NewPriorityProperties(appConfig, defaults))
 
 	// 加载系统环境变量，第 3 层
 	sysEnv := app.loadSystemEnv()
@@ -238,25 +345,89 @@ func (app *application) prepare() {
 	cmdArgs := app.loadCmdArgs()
 	p.InsertBefore(cmdArgs, sysEnv)
 
-	// 加载特定环境的配置文件，如 application-test.properties
-	profile := app.appCtx.GetProfile()
-	if profile == "" {
+	// 加载特定环境的配置文件，如 application-test.properties，第 4 层
+	profiles := app.profiles
+	if len(profiles) == 0 {
 		keys := []string{SpringProfile, SPRING_PROFILE}
-		profile = p.GetStringProperty(keys...)
+		profiles = propertyList(p.GetProperty(keys...))
 	}
-	if profile != "" {
-		app.appCtx.SetProfile(profile) // 第 4 层
-		profileConfig := app.loadProfileConfig(profile)
-		p.InsertBefore(profileConfig, appConfig)
+	profiles = expandProfileGroups(p, profiles)
+
+	// 按照声明的顺序加载运行环境的配置文件，后面的覆盖前面的
+	upper := appConfig
+	for _, s := range profiles {
+		profileConfig := app.loadProfileConfig(app.sources, s, profiles)
+		p.InsertBefore(profileConfig, upper)
+		upper = profileConfig
+	}
+
+	// 加载 spring.profiles.include 包含的运行环境的配置文件，它们位于当前运行环境
+	// 和默认配置文件之间，后包含的覆盖先包含的，被包含的运行环境还可以继续包含
+	// 其他运行环境，并且被后者覆盖。
+	lower := appConfig
+	included := map[string]bool{"": true}
+	for _, s := range profiles {
+		included[s] = true
+	}
+
+	var include func(profiles []string)
+	include = func(includes []string) {
+		for _, s := range expandProfileGroups(p, includes) {
+			if included[s] {
+				continue
+			}
+			included[s] = true
+			profiles = append(profiles, s)
+			SpringLogger.Infof("include profile %s", s)
+			config := app.loadProfileConfig(app.sources, s, profiles)
+			include(propertyList(config.GetProperty(SpringProfilesInclude)))
+			p.InsertBefore(config, lower)
+			lower = config
+		}
+	}
+
+	keys := []string{SpringProfilesInclude, SPRING_PROFILES_INCLUDE}
+	include(propertyList(p.GetProperty(keys...)))
+	return p, profiles
+}
+
+// prepare 准备上下文环境
+func (app *application) prepare() {
+
+	// 解析命令行参数，程序名不是参数
+	cmdLine, err := parseCommandLine(os.Args[1:], booleanProperties(app.modules))
+	if err != nil {
+		panic(err)
+	}
+	app.cmdLine = cmdLine
+
+	// 将通过代码设置的属性值拷贝一份，第 1 层
+	app.apiConfig = SpringCore.NewDefaultProperties()
+	copyProperties(app.appCtx, app.apiConfig, "api")
+	app.profiles = app.appCtx.GetProfiles()
+
+	// 创建配置路径对应的属性源
+	app.sources = app.loadPropertySources()
+
+	p, profiles := app.loadProperties()
+	app.appCtx.SetProfiles(profiles...)
+
+	// 使用重组后的属性值及其来源替换 SpringContext 属性列表，属性值中的引用在读取时
+	// 解析。此后通过代码设置的属性值，比如 Config 函数设置的属性值，在重新加载时保留。
+	if _, err := app.appCtx.RefreshProperties(p); err != nil {
+		panic(err)
 	}
 
-	// 将重组后的属性值写入 SpringContext 属性列表
-	properties := p.GetProperties()
-	for key, value := range properties {
-		value = resolveProperty(properties, key, value)
-		app.appCtx.SetProperty(key, value)
+	// 使用配置的秘钥解密 ENC(...) 形式的属性值
+	if d, err := loadPropertyDecryptor(app.appCtx); err != nil {
+		panic(err)
+	} else if d != nil {
+		SpringCore.SetPropertyDecryptor(d)
 	}
 
+	// 打印最终生效的属性值及其来源
+	app.printEffectiveConfig()
+
 	// 设置是否允许注入私有字段
 	if ok := app.appCtx.AllAccess(); !ok {
 		keys := []string{SpringAccess, SPRING_ACCESS}
@@ -264,6 +435,149 @@ func (app *application) prepare() {
 			app.appCtx.SetAllAccess(strings.ToLower(access) == "all")
 		}
 	}
+
+	// 开启属性值的动态刷新，属性源变化或者收到 SIGHUP 信号时重新加载属性值
+	keys := []string{SpringConfigReloadEnabled, SPRING_CONFIG_RELOAD_ENABLED}
+	if app.appCtx.GetBoolProperty(keys...) {
+		app.watchPropertySources()
+		app.watchSignal()
+	}
+}
+
+// Reload 重新加载所有配置层的属性值，属性值发生变化时重新绑定可刷新 Bean 的
+// 属性值并发布属性变化事件。加载或者绑定失败时返回错误，属性值和 Bean 保持不变。
+// 解密器使用新的属性值重新创建；运行环境决定了哪些 Bean 和配置生效，而条件和 Bean
+// 不会重新求值，因此重新加载之后运行环境发生变化时返回错误，需要重启应用。
+func (app *application) Reload() (err error) {
+	app.reloadMutex.Lock()
+	defer app.reloadMutex.Unlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+		if err != nil {
+			SpringLogger.Errorf("reload properties error: %v", err)
+		}
+	}()
+
+	p, profiles := app.loadProperties()
+
+	if curr := app.appCtx.GetProfiles(); strings.Join(profiles, ",") != strings.Join(curr, ",") {
+		return fmt.Errorf("profiles can't be changed by reload, %v -> %v, restart the application", curr, profiles)
+	}
+
+	// 使用新的属性值创建解密器，刷新失败时恢复原来的解密器
+	if d, err := loadPropertyDecryptor(p); err != nil {
+		return err
+	} else if d != nil {
+		old := SpringCore.GetPropertyDecryptor()
+		SpringCore.SetPropertyDecryptor(d)
+		defer func() {
+			if err != nil {
+				SpringCore.SetPropertyDecryptor(old)
+			}
+		}()
+	}
+
+	changed, err := app.appCtx.RefreshProperties(p)
+	if err != nil || len(changed) == 0 {
+		return err
+	}
+
+	SpringLogger.Infof("properties reloaded, changed keys: %v", changed)
+	app.printEffectiveConfig()
+	app.printConfigurationProperties()
+
+	event := PropertyChangeEvent{Keys: changed}
+	for _, l := range app.Listeners {
+		l.OnPropertyChange(app.appCtx, event)
+	}
+	return nil
+}
+
+// watchPropertySources 监听支持变化通知的属性源，属性源发生变化时重新加载属性值
+func (app *application) watchPropertySources() {
+	for _, source := range app.sources {
+		if w, ok := source.(WatchablePropertySource); ok {
+			name := w.Name()
+			stop := w.Watch(func() {
+				SpringLogger.Infof("property source %s changed", name)
+				_ = app.Reload()
+			})
+			app.stopWatch = append(app.stopWatch, stop)
+		}
+	}
+}
+
+// watchSignal 收到 SIGHUP 信号时重新加载属性值
+func (app *application) watchSignal() {
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-sig:
+				SpringLogger.Info("got SIGHUP, reload properties")
+				_ = app.Reload()
+			}
+		}
+	}()
+
+	app.stopWatch = append(app.stopWatch, func() {
+		signal.Stop(sig)
+		close(done)
+	})
+}
+
+// printEffectiveConfig 在 debug 级别打印最终生效的属性值及其来源，敏感的属性值不打印
+func (app *application) printEffectiveConfig() {
+
+	properties := maskProperties(app.appCtx)
+	keys := make([]string, 0, len(properties))
+	for k := range properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	SpringLogger.Debug("effective configuration:")
+	for _, k := range keys {
+		v := properties[k]
+		if origin, ok := app.appCtx.GetPropertyOrigin(k); ok {
+			SpringLogger.Debugf("  %s=%v <- %s", k, v, origin)
+		} else {
+			SpringLogger.Debugf("  %s=%v", k, v)
+		}
+	}
+}
+
+// printConfigurationProperties 在 debug 级别打印配置属性 Bean 及其绑定的属性值，
+// 加密属性值不打印
+func (app *application) printConfigurationProperties() {
+
+	var beans []*SpringCore.BeanDefinition
+	for _, bd := range app.appCtx.GetBeanDefinitions() {
+		if _, ok := bd.ConfigurationPrefix(); ok {
+			beans = append(beans, bd)
+		}
+	}
+
+	sort.Slice(beans, func(i, j int) bool {
+		return beans[i].BeanId() < beans[j].BeanId()
+	})
+
+	for _, bd := range beans {
+		prefix, _ := bd.ConfigurationPrefix()
+		SpringLogger.Debugf("configuration properties %s (%s):", prefix, bd.BeanId())
+		for _, p := range SpringCore.CollectConfigurationProperties(app.appCtx, bd) {
+			SpringLogger.Debugf("  %s=%v <- %s", p.Name, p.Value, p.Field)
+		}
+	}
 }
 
 func (app *application) stopApplication() {
@@ -277,6 +591,11 @@ func (app *application) ShutDown() {
 
 	SpringLogger.Info("spring boot exiting")
 
+	// 停止监听属性源
+	for _, stop := range app.stopWatch {
+		stop()
+	}
+
 	// OnStopApplication 是否需要有 Timeout 的 Context？
 	// 仔细想想没有必要，程序想要优雅退出就得一直等，等到所有工作
 	// 做完，用户如果等不急了可以使用 kill -9 进行硬杀，也就是
@@ -0,0 +1,196 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringBoot
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/go-spring/go-spring-parent/spring-utils"
+	"github.com/go-spring/go-spring/spring-core"
+)
+
+// numberArg 负数形式的参数，它是属性值而不是选项
+var numberArg = regexp.MustCompile(`^-[0-9.]`)
+
+// commandLine 解析后的命令行参数
+type commandLine struct {
+	keys    []string            // 属性名，按照第一次出现的顺序
+	values  map[string][]string // 属性名 -> 属性值，重复的属性名收集为列表
+	sources map[string]string   // 属性名 -> 第一次出现的参数
+	args    []string            // 位置参数，包括 -- 之后的所有参数
+	command string              // 代替启动应用执行的命令，如 help
+}
+
+const (
+	helpCommand           = "help"            // 打印帮助信息
+	configMetadataCommand = "config-metadata" // 打印属性元数据
+	configSchemaCommand   = "config-schema"   // 打印属性的 JSON Schema
+	encryptCommand        = "encrypt"         // 加密位置参数中的属性值
+)
+
+// parseCommandLine 解析命令行参数，args 不包含程序名。支持的形式有：
+//
+// --key=value、-key=value：属性名和属性值；
+// --key value、-key value：下一个参数不是选项并且 key 不是已知的布尔型属性时作为
+// 属性值，负数不是选项；
+// --key、-key：之后没有属性值的选项以及已知的布尔型属性是布尔开关，属性值为 true；
+// -Dkey=value：Java 风格的属性，必须包含 =，否则按照 -key 的形式解析，比如 -Debug；
+// --：之后的参数全部作为位置参数；
+// --help、-h：打印帮助信息；
+// --config-metadata：以 JSON 格式打印属性元数据；
+// --config-schema：打印属性的 JSON Schema，用于编辑器补全和校验配置文件；
+// --encrypt：加密位置参数，打印 ENC(...) 形式的属性值。
+//
+// 属性名转换为规范形式，重复出现的属性名收集为属性值列表，不属于任何选项的参数
+// 作为位置参数。booleans 是已知的布尔型属性的规范形式的属性名。
+func parseCommandLine(args []string, booleans map[string]bool) (*commandLine, error) {
+
+	c := &commandLine{
+		values:  make(map[string][]string),
+		sources: make(map[string]string),
+	}
+
+	isOption := func(arg string) bool {
+		return strings.HasPrefix(arg, "-") && arg != "-" && !numberArg.MatchString(arg)
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == "--" {
+			c.args = append(c.args, args[i+1:]...)
+			break
+		}
+
+		if !isOption(arg) {
+			c.args = append(c.args, arg)
+			continue
+		}
+
+		switch arg {
+		case "--help", "-h":
+			c.command = helpCommand
+			continue
+		case "--" + configMetadataCommand:
+			c.command = configMetadataCommand
+			continue
+		case "--" + configSchemaCommand:
+			c.command = configSchemaCommand
+			continue
+		case "--" + encryptCommand:
+			c.command = encryptCommand
+			continue
+		}
+
+		var (
+			key   string
+			value string
+		)
+
+		switch {
+		case strings.HasPrefix(arg, "-D") && strings.Contains(arg, "="):
+			key = arg[2:]
+			j := strings.Index(key, "=")
+			key, value = key[:j], key[j+1:]
+		default:
+			key = strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+			if j := strings.Index(key, "="); j >= 0 {
+				key, value = key[:j], key[j+1:]
+			} else if i < len(args)-1 && !isOption(args[i+1]) && !booleans[SpringCore.CanonicalPropertyKey(key)] {
+				value = args[i+1]
+				i++
+			} else {
+				value = "true"
+			}
+		}
+
+		if key == "" || strings.HasPrefix(key, "-") {
+			return nil, fmt.Errorf("invalid command line argument \"%s\"", arg)
+		}
+
+		key = SpringCore.CanonicalPropertyKey(key)
+		if _, ok := c.values[key]; !ok {
+			c.keys = append(c.keys, key)
+			c.sources[key] = arg
+		}
+		c.values[key] = append(c.values[key], value)
+	}
+	return c, nil
+}
+
+// properties 返回命令行中的属性值及其来源，重复的属性名的属性值为字符串列表。
+func (c *commandLine) properties() SpringCore.Properties {
+	p := SpringCore.NewDefaultProperties()
+	for _, key := range c.keys {
+		var value interface{} = c.values[key][0]
+		if values := c.values[key]; len(values) > 1 {
+			value = values
+		}
+		origin := SpringCore.PropertyOrigin{Layer: "cmd-args", Source: c.sources[key]}
+		p.SetPropertyWithOrigin(key, value, origin)
+	}
+	return p
+}
+
+// printHelp 打印帮助信息，列出 spring 内置的选项以及通过 value 标签声明的属性。
+func printHelp(w io.Writer, program string, metadata []SpringCore.PropertyMetadata) {
+
+	fmt.Fprintf(w, "Usage: %s [options] [--] [args...]\n\n", filepath.Base(program))
+	fmt.Fprintln(w, "Options:")
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	for _, m := range metadata {
+		usage := m.Description
+		if m.HasDefault {
+			usage = strings.TrimSpace(fmt.Sprintf("%s (default: %s)", usage, m.DefaultValue))
+		}
+		fmt.Fprintf(tw, "  --%s=<%s>\t%s\n", m.Name, m.Type, usage)
+	}
+
+	fmt.Fprintf(tw, "  --help, -h\t%s\n", "打印帮助信息")
+	fmt.Fprintf(tw, "  --%s\t%s\n", configMetadataCommand, "以 JSON 格式打印属性元数据")
+	fmt.Fprintf(tw, "  --%s\t%s\n", configSchemaCommand, "打印属性的 JSON Schema")
+	fmt.Fprintf(tw, "  --%s <value>...\t%s\n", encryptCommand, "加密属性值")
+	_ = tw.Flush()
+}
+
+// runCommand 执行命令行中的命令，然后退出程序
+func runCommand(w io.Writer, c *commandLine, program string, metadata []SpringCore.PropertyMetadata) {
+	var err error
+	switch c.command {
+	case helpCommand:
+		printHelp(w, program, metadata)
+	case configMetadataCommand:
+		err = writeJSON(w, metadata)
+	case configSchemaCommand:
+		err = writeJSON(w, PropertyMetadataSchema(metadata))
+	case encryptCommand:
+		err = encryptValues(w, c.args)
+	}
+	SpringUtils.Panic(err).When(err != nil)
+	exitAfterCommand()
+}
+
+// exitAfterCommand 执行命令之后退出程序，测试时可以替换
+var exitAfterCommand = func() { os.Exit(0) }
@@ -0,0 +1,144 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringBoot
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/go-spring/go-spring/spring-core"
+	"github.com/magiconair/properties/assert"
+)
+
+type argsServerConfig struct {
+	Port      int  `value:"${port:=8080}"`
+	EnableSSL bool `value:"${ssl.enable:=false}"`
+}
+
+func TestParseCommandLine(t *testing.T) {
+
+	t.Run("options", func(t *testing.T) {
+		c, err := parseCommandLine([]string{
+			"--web.server.port=9000",
+			"-Dspring.profile=prod",
+			"-DskipTests=true",
+			"-Debug",
+			"--offset", "-5",
+			"--ratio=-0.5",
+			"--name", "go-spring",
+			"-maxIdleConns", "10",
+			"--verbose",
+			"--tag", "a", "--tag=b", "-Dtag=c",
+			"--empty=",
+			"input.txt",
+			"--", "--not-option", "-x",
+		}, nil)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, c.command, "")
+		assert.Equal(t, c.args, []string{"input.txt", "--not-option", "-x"})
+
+		p := c.properties()
+		assert.Equal(t, p.GetProperty("web.server.port"), "9000")
+		assert.Equal(t, p.GetProperty("spring.profile"), "prod")
+		assert.Equal(t, p.GetProperty("skip-tests"), "true")
+		assert.Equal(t, p.GetProperty("ebug"), nil)
+		assert.Equal(t, p.GetProperty("debug"), "true")
+		assert.Equal(t, p.GetIntProperty("offset"), int64(-5))
+		assert.Equal(t, p.GetFloatProperty("ratio"), -0.5)
+		assert.Equal(t, p.GetProperty("name"), "go-spring")
+		assert.Equal(t, p.GetProperty("max-idle-conns"), "10")
+		assert.Equal(t, p.GetBoolProperty("verbose"), true)
+		assert.Equal(t, p.GetProperty("tag"), []string{"a", "b", "c"})
+		assert.Equal(t, p.GetProperty("empty"), "")
+
+		origin, _ := p.GetPropertyOrigin("tag")
+		assert.Equal(t, origin.String(), "--tag [cmd-args]")
+	})
+
+	t.Run("boolean flag before option", func(t *testing.T) {
+		c, err := parseCommandLine([]string{"--debug", "--port", "8080", "-h"}, nil)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, c.command, helpCommand)
+		assert.Equal(t, c.properties().GetProperties(), map[string]interface{}{
+			"debug": "true",
+			"port":  "8080",
+		})
+	})
+
+	t.Run("known boolean flag", func(t *testing.T) {
+		booleans := map[string]bool{"verbose": true}
+		c, err := parseCommandLine([]string{"--verbose", "input.txt", "--name", "go-spring"}, booleans)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, c.args, []string{"input.txt"})
+		assert.Equal(t, c.properties().GetProperties(), map[string]interface{}{
+			"verbose": "true",
+			"name":    "go-spring",
+		})
+
+		assert.Equal(t, booleanProperties(nil)[SpringConfigReloadEnabled], true)
+
+		// 自动配置模块的配置属性 Bean 声明的布尔型属性
+		m := NewModule("module-web")
+		m.RegisterConfigurationProperties("web.server", new(argsServerConfig))
+		booleans = booleanProperties([]*Module{m})
+		assert.Equal(t, booleans["web.server.ssl.enable"], true)
+		assert.Equal(t, booleans["web.server.port"], false)
+	})
+
+	t.Run("command", func(t *testing.T) {
+		c, err := parseCommandLine([]string{"--config-schema", "--port=8080"}, nil)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, c.command, configSchemaCommand)
+		assert.Equal(t, c.properties().GetProperty("port"), "8080")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, arg := range []string{"--=1", "---key=1", "-D=1"} {
+			_, err := parseCommandLine([]string{arg}, nil)
+			assert.Matches(t, err.Error(), "invalid command line argument")
+		}
+	})
+}
+
+type helpServerConfig struct {
+	Host string `value:"${host:=localhost}"`
+	Port int    `value:"${port:=8080}"`
+}
+
+type helpServer struct {
+	Name   string           `value:"${server.name}"`
+	Config helpServerConfig `value:"${server}"`
+}
+
+func TestPrintHelp(t *testing.T) {
+	ctx := SpringCore.NewDefaultSpringContext()
+	ctx.RegisterBean(new(helpServer))
+
+	var buf bytes.Buffer
+	printHelp(&buf, "/usr/local/bin/my-app", collectPropertyMetadata(ctx))
+
+	lines := strings.Split(buf.String(), "\n")
+	assert.Equal(t, lines[0], "Usage: my-app [options] [--] [args...]")
+	assert.Matches(t, buf.String(), `(?m)^  --spring.profile=<string> +设置运行环境`)
+	assert.Matches(t, buf.String(), `(?m)^  --server.host=<string> +\(default: localhost\)$`)
+	assert.Matches(t, buf.String(), `(?m)^  --server.name=<string> *$`)
+	assert.Matches(t, buf.String(), `(?m)^  --server.port=<int> +\(default: 8080\)$`)
+	assert.Matches(t, buf.String(), `(?m)^  --spring.config.reload.enabled=<bool> +是否开启属性值的动态刷新 \(default: false\)$`)
+	assert.Matches(t, buf.String(), `(?m)^  --help, -h +打印帮助信息$`)
+	assert.Matches(t, buf.String(), `(?m)^  --config-schema +打印属性的 JSON Schema$`)
+}
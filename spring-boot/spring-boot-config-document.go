@@ -0,0 +1,174 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringBoot
+
+import (
+	"strings"
+
+	"github.com/go-spring/go-spring/spring-core"
+)
+
+const (
+	SpringConfigActivateOnProfile = "spring.config.activate.on-profile" // 文档生效的运行环境
+	SpringProfiles                = "spring.profiles"                   // 文档生效的运行环境，旧的写法
+)
+
+// documentSplitter 支持多文档的读取器，比如使用 --- 分隔文档的 yaml
+type documentSplitter interface {
+	// splitDocuments 将配置内容拆分为多个文档，同时返回每个文档之前的行数，
+	// 用于将文档内的行号转换为配置内容的行号。
+	splitDocuments(buffer []byte) (docs [][]byte, offsets []int)
+}
+
+// configDocument 配置内容中的一个文档
+type configDocument struct {
+	properties SpringCore.Properties // 文档中的属性值及其来源
+	profiles   []string              // 文档声明的运行环境，为空表示总是生效
+}
+
+// documentActivator 判断文档是否生效，profiles 是文档声明的运行环境。
+type documentActivator func(profiles []string) bool
+
+// activateFor 返回加载 profile 运行环境的配置时使用的文档判断函数，active 是按照
+// 声明顺序排列的所有生效的运行环境。profile 为空时只有没有声明运行环境的文档生效；
+// 否则声明的运行环境可以是运行环境表达式，如 prod & !eu，表达式针对所有生效的运行
+// 环境求值。运行环境专属的配置文件，比如 application-test.yaml，只在该运行环境下
+// 加载，其中没有声明运行环境的文档以及表达式成立的文档生效。其他配置文件在每个运行
+// 环境下都会读取，为了使文档只生效一次，表达式成立的文档只在使它成立的运行环境下
+// 生效，见 activatingProfile。
+func activateFor(profile string, active []string, profileSpecific bool) documentActivator {
+
+	// 正在加载的运行环境总是生效的
+	if profile != "" && !containsProfile(active, profile) {
+		active = append(active[:len(active):len(active)], profile)
+	}
+
+	return func(profiles []string) bool {
+		if len(profiles) == 0 {
+			return profile == "" || profileSpecific
+		}
+		if profile == "" {
+			return false
+		}
+		if profileSpecific {
+			return matchAnyProfiles(profiles, active)
+		}
+		return activatingProfile(profiles, active) == profile
+	}
+}
+
+// activatingProfile 返回使文档生效的运行环境，即按照声明的顺序依次启用运行环境时，
+// 文档声明的表达式开始成立的那个运行环境。文档在所有生效的运行环境下不成立时返回空
+// 字符串，比如 prod & !eu 在 prod、eu 同时生效时不成立。
+func activatingProfile(profiles []string, active []string) string {
+	if !matchAnyProfiles(profiles, active) {
+		return ""
+	}
+	for i := range active {
+		if matchAnyProfiles(profiles, active[:i+1]) {
+			return active[i]
+		}
+	}
+	return ""
+}
+
+// matchAnyProfiles 返回文档声明的任一运行环境表达式是否针对 active 成立
+func matchAnyProfiles(profiles []string, active []string) bool {
+	for _, s := range profiles {
+		if SpringCore.MatchProfiles(s, active) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsProfile 返回 profiles 中是否包含 profile
+func containsProfile(profiles []string, profile string) bool {
+	for _, s := range profiles {
+		if s == profile {
+			return true
+		}
+	}
+	return false
+}
+
+// readConfigDocuments 使用 reader 读取配置内容中的所有文档，按照文档的顺序返回，
+// 并记录每个属性值的来源，source 是配置内容的出处，如文件名、config-map 的 key
+// 等。文档声明的运行环境不作为属性值返回。
+func readConfigDocuments(reader ConfigReader, buffer []byte, source string) []configDocument {
+
+	parts, offsets := [][]byte{buffer}, []int{0}
+	if s, ok := reader.(documentSplitter); ok {
+		parts, offsets = s.splitDocuments(buffer)
+	}
+
+	var docs []configDocument
+	for i, part := range parts {
+
+		result := make(map[string]interface{})
+		reader.ReadBuffer(part, result)
+
+		var lines map[string]int
+		if l, ok := reader.(keyLocator); ok {
+			lines = l.locateKeys(part)
+		}
+
+		var onProfile, profiles interface{}
+		doc := configDocument{properties: SpringCore.NewDefaultProperties()}
+
+		for k, v := range result {
+			switch strings.ToLower(k) {
+			case SpringConfigActivateOnProfile:
+				onProfile = v
+				continue
+			case SpringProfiles:
+				profiles = v
+				continue
+			}
+
+			line := lines[strings.ToLower(k)]
+			if line > 0 {
+				line += offsets[i]
+			}
+			doc.properties.SetPropertyWithOrigin(k, v, SpringCore.PropertyOrigin{Source: source, Line: line})
+		}
+
+		if onProfile != nil {
+			profiles = onProfile
+		}
+		doc.profiles = propertyList(profiles)
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+// loadConfigDocuments 读取配置内容中生效的文档，后面的文档覆盖前面的文档。
+func loadConfigDocuments(reader ConfigReader, buffer []byte, source string, activate documentActivator, out SpringCore.Properties) {
+	for _, doc := range readConfigDocuments(reader, buffer, source) {
+		if activate(doc.profiles) {
+			copyDocument(doc, out)
+		}
+	}
+}
+
+// copyDocument 将文档中的属性值及其来源拷贝到 out
+func copyDocument(doc configDocument, out SpringCore.Properties) {
+	for k, v := range doc.properties.GetProperties() {
+		origin, _ := doc.properties.GetPropertyOrigin(k)
+		out.SetPropertyWithOrigin(doc.properties.GetPropertyName(k), v, origin)
+	}
+}
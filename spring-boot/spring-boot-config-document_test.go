@@ -0,0 +1,137 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringBoot
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-spring/go-spring/spring-core"
+	"github.com/magiconair/properties/assert"
+)
+
+const multiDocumentYaml = `server:
+  port: 8080
+  host: localhost
+---
+spring:
+  config:
+    activate:
+      on-profile: prod
+server:
+  port: 80
+---
+spring.profiles: [test, dev]
+server:
+  port: 8081
+---
+# 后面的文档覆盖前面的文档
+spring.config.activate.on-profile: prod
+server:
+  host: prod-host
+`
+
+func TestConfigDocuments(t *testing.T) {
+
+	t.Run("split", func(t *testing.T) {
+		docs := readConfigDocuments(configReaders[".yaml"], []byte(multiDocumentYaml), "application.yaml")
+		assert.Equal(t, len(docs), 4)
+		assert.Equal(t, docs[0].profiles, []string(nil))
+		assert.Equal(t, docs[1].profiles, []string{"prod"})
+		assert.Equal(t, docs[2].profiles, []string{"test", "dev"})
+		assert.Equal(t, docs[3].profiles, []string{"prod"})
+
+		// 文档声明的运行环境不作为属性值
+		assert.Equal(t, docs[1].properties.GetProperties(), map[string]interface{}{"server.port": 80})
+
+		origin, _ := docs[3].properties.GetPropertyOrigin("server.host")
+		assert.Equal(t, origin.Line, 19)
+	})
+
+	t.Run("activate", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "config-document")
+		assert.Equal(t, err, nil)
+		defer os.RemoveAll(dir)
+
+		writeConfigTree(t, dir, map[string]string{
+			"application.yaml":      multiDocumentYaml,
+			"application-dev.yaml":  "server:\n  port: 9090\n---\nspring.profiles: test\nserver:\n  host: test-host\n",
+			"application-prod.yaml": "server:\n  host: prod-file-host\n",
+		})
+
+		p := NewDefaultPropertySource(dir)
+		assert.Equal(t, p.Load("", nil).GetProperties(), map[string]interface{}{
+			"server.port": 8080,
+			"server.host": "localhost",
+		})
+
+		// 运行环境专属的配置文件覆盖 application 配置文件中的文档
+		result := p.Load("prod", nil)
+		assert.Equal(t, result.GetProperties(), map[string]interface{}{
+			"server.port": 80,
+			"server.host": "prod-file-host",
+		})
+
+		result = p.Load("dev", nil)
+		assert.Equal(t, result.GetProperties(), map[string]interface{}{"server.port": 9090})
+
+		result = p.Load("test", nil)
+		assert.Equal(t, result.GetProperties(), map[string]interface{}{"server.port": 8081})
+		origin, _ := result.GetPropertyOrigin("server.port")
+		assert.Equal(t, origin.String(), filepath.Join(dir, "application.yaml")+":14")
+	})
+
+	t.Run("expressions", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "config-document")
+		assert.Equal(t, err, nil)
+		defer os.RemoveAll(dir)
+
+		writeConfigTree(t, dir, map[string]string{
+			"application.yaml": "a: base\n" +
+				"---\nspring.config.activate.on-profile: prod & metrics\na: both\n" +
+				"---\nspring.config.activate.on-profile: prod & !eu\nb: noneu\n" +
+				"---\nspring.config.activate.on-profile: prod | eu\nc: any\n",
+			"application-eu.yaml": "d: eu\n---\nspring.config.activate.on-profile: metrics & !us\ne: metrics\n",
+		})
+
+		// 表达式针对所有生效的运行环境求值，每个文档只在使它成立的运行环境下生效一次
+		active := []string{"prod", "metrics", "eu"}
+		p := NewDefaultPropertySource(dir)
+		assert.Equal(t, p.Load("prod", active).GetProperties(), map[string]interface{}{"c": "any"})
+		assert.Equal(t, p.Load("metrics", active).GetProperties(), map[string]interface{}{"a": "both"})
+		assert.Equal(t, p.Load("eu", active).GetProperties(), map[string]interface{}{"d": "eu", "e": "metrics"})
+
+		// 只有 prod 生效时 prod & !eu 成立
+		assert.Equal(t, p.Load("prod", []string{"prod"}).GetProperties(), map[string]interface{}{"b": "noneu", "c": "any"})
+
+		app := newApplication(&defaultApplicationContext{
+			SpringContext: SpringCore.NewDefaultSpringContext(),
+		}, dir)
+		app.apiConfig = SpringCore.NewDefaultProperties()
+		app.profiles = active
+		app.cmdLine, _ = parseCommandLine(nil, nil)
+		app.sources = app.loadPropertySources()
+
+		result, profiles := app.loadProperties()
+		assert.Equal(t, profiles, active)
+		assert.Equal(t, result.GetProperty("a"), "both")
+		assert.Equal(t, result.GetProperty("b"), nil)
+		assert.Equal(t, result.GetProperty("c"), "any")
+	})
+}
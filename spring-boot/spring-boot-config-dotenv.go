@@ -0,0 +1,241 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringBoot
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/go-spring/go-spring-parent/spring-logger"
+	"github.com/go-spring/go-spring-parent/spring-utils"
+	"github.com/go-spring/go-spring/spring-core"
+)
+
+func init() {
+	RegisterPropertySourceFactory("dotenv", func(location string) PropertySource {
+		return NewDotenvPropertySource(location)
+	})
+}
+
+// dotenvKey .env 文件中合法的变量名
+var dotenvKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.\-]*$`)
+
+// dotenvEntry .env 文件中的一个变量
+type dotenvEntry struct {
+	key   string // 转换之后的属性名
+	value string // 变量值
+	line  int    // 变量所在的行号，从 1 开始
+}
+
+// parseDotenv 解析 .env 文件的内容，支持的语法有：
+//
+// KEY=value、export KEY=value：变量定义，变量名按照 SpringCore.EnvToPropertyKey
+// 的规则转换为属性名；
+// # comment、KEY=value # comment：整行注释和未加引号的变量值之后的注释；
+// KEY='value'：单引号中的内容原样保留，不解析 ${} 引用；
+// KEY="value"：双引号中支持 \n、\t、\r、\" 和 \\ 转义。
+//
+// 引号中的变量值可以跨越多行，未加引号的变量值去掉首尾的空白字符。
+func parseDotenv(buffer []byte) ([]dotenvEntry, error) {
+
+	lines := strings.Split(string(buffer), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+
+	var entries []dotenvEntry
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "export ") || strings.HasPrefix(line, "export\t") {
+			line = strings.TrimSpace(line[len("export"):])
+		}
+
+		eq := strings.Index(line, "=")
+		if eq <= 0 {
+			return nil, fmt.Errorf("invalid dotenv line %d: \"%s\"", lineNo, lines[i])
+		}
+
+		key := strings.TrimSpace(line[:eq])
+		if !dotenvKey.MatchString(key) {
+			return nil, fmt.Errorf("invalid dotenv key at line %d: \"%s\"", lineNo, key)
+		}
+
+		value := strings.TrimLeft(line[eq+1:], " \t")
+
+		if value != "" && (value[0] == '\'' || value[0] == '"') {
+			quote := value[0]
+			text := value[1:]
+
+			// 查找结束的引号，没有找到时继续读取下一行
+			end := closingQuote(text, quote)
+			for end < 0 && i+1 < len(lines) {
+				i++
+				text += "\n" + lines[i]
+				end = closingQuote(text, quote)
+			}
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quoted value at dotenv line %d", lineNo)
+			}
+
+			rest := strings.TrimSpace(text[end+1:])
+			if rest != "" && !strings.HasPrefix(rest, "#") {
+				return nil, fmt.Errorf("unexpected \"%s\" after quoted value at dotenv line %d", rest, lineNo)
+			}
+
+			if quote == '\'' {
+				value = strings.Replace(text[:end], "${", `\${`, -1)
+			} else {
+				value = unescapeDotenv(text[:end])
+			}
+
+		} else {
+			for _, sep := range []string{" #", "\t#"} {
+				if j := strings.Index(value, sep); j >= 0 {
+					value = value[:j]
+				}
+			}
+			value = strings.TrimSpace(value)
+		}
+
+		entries = append(entries, dotenvEntry{
+			key:   SpringCore.EnvToPropertyKey(key),
+			value: value,
+			line:  lineNo,
+		})
+	}
+	return entries, nil
+}
+
+// closingQuote 返回结束引号的位置，双引号中的 \" 不是结束引号。
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case s[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// unescapeDotenv 处理双引号中的转义字符，其他的 \ 原样保留，比如 \${ 转义。
+func unescapeDotenv(s string) string {
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			buf.WriteByte(s[i])
+			continue
+		}
+		switch s[i+1] {
+		case 'n':
+			buf.WriteByte('\n')
+		case 't':
+			buf.WriteByte('\t')
+		case 'r':
+			buf.WriteByte('\r')
+		case '"', '\\':
+			buf.WriteByte(s[i+1])
+		default:
+			buf.WriteByte('\\')
+			buf.WriteByte(s[i+1])
+		}
+		i++
+	}
+	return buf.String()
+}
+
+// DotenvReader 读取 .env 格式的配置文件
+type DotenvReader struct{}
+
+func (r *DotenvReader) ReadFile(filename string, out map[string]interface{}) {
+	file, err := ioutil.ReadFile(filename)
+	SpringUtils.Panic(err).When(err != nil)
+	r.ReadBuffer(file, out)
+}
+
+func (r *DotenvReader) ReadBuffer(buffer []byte, out map[string]interface{}) {
+	entries, err := parseDotenv(buffer)
+	SpringUtils.Panic(err).When(err != nil)
+	for _, e := range entries {
+		out[e.key] = e.value
+	}
+}
+
+// locateKeys 返回属性名到行号的映射，重复定义的变量返回最后一次定义的行号。
+func (r *DotenvReader) locateKeys(buffer []byte) map[string]int {
+	entries, _ := parseDotenv(buffer)
+	lines := make(map[string]int)
+	for _, e := range entries {
+		lines[e.key] = e.line
+	}
+	return lines
+}
+
+// dotenvPropertySource 基于 .env 文件的属性源，通常用于本地开发。.env 文件只属于
+// 默认的 profile，文件不存在时返回空的属性列表。
+type dotenvPropertySource struct {
+	filename string        // .env 文件名称
+	snapshot *fileSnapshot // .env 文件的快照
+}
+
+// NewDotenvPropertySource dotenvPropertySource 的构造函数
+func NewDotenvPropertySource(filename string) *dotenvPropertySource {
+	return &dotenvPropertySource{
+		filename: filename,
+		snapshot: newFileSnapshot(),
+	}
+}
+
+// Name 返回属性源的名称
+func (p *dotenvPropertySource) Name() string {
+	return "dotenv"
+}
+
+// Priority 返回属性源的优先级，.env 文件覆盖同一配置层中的普通配置文件。
+func (p *dotenvPropertySource) Priority() int {
+	return dotenvPropertySourcePriority
+}
+
+// Load 加载 .env 文件，profile 配置文件剖面。
+func (p *dotenvPropertySource) Load(profile string, active []string) SpringCore.Properties {
+
+	result := SpringCore.NewDefaultProperties()
+
+	// 不存在的文件也要记录快照，这样才能发现新增的文件
+	p.snapshot.add(p.filename)
+	if profile != "" || !p.snapshot.exists(p.filename) {
+		return result
+	}
+
+	SpringLogger.Info("load properties from dotenv ", p.filename)
+	loadConfigFile(p.filename, new(DotenvReader), activateFor("", nil, false), result, p.snapshot)
+	return result
+}
+
+// Watch 轮询 .env 文件，文件发生变化时调用 onChange。
+func (p *dotenvPropertySource) Watch(onChange func()) (stop func()) {
+	return pollWatch(fileWatchInterval, p.snapshot.changed, onChange)
+}
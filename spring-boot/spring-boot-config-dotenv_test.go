@@ -0,0 +1,103 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringBoot
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-spring/go-spring/spring-core"
+	"github.com/magiconair/properties/assert"
+)
+
+func TestParseDotenv(t *testing.T) {
+
+	t.Run("syntax", func(t *testing.T) {
+		entries, err := parseDotenv([]byte("# comment\n" +
+			"DB_URL=mysql://localhost # inline comment\n" +
+			"export WEB_SERVER_PORT=8080\r\n" +
+			"\n" +
+			"NAME = 'go ${spring}' \n" +
+			"GREETING=\"hello\\n\\\"world\\\" \\${user}\"\n" +
+			"CERT=\"-----BEGIN-----\n" +
+			"abc\n" +
+			"-----END-----\" # certificate\n" +
+			"app.name=go-spring\n" +
+			"EMPTY=\n"))
+		assert.Equal(t, err, nil)
+		assert.Equal(t, entries, []dotenvEntry{
+			{key: "db.url", value: "mysql://localhost", line: 2},
+			{key: "web.server.port", value: "8080", line: 3},
+			{key: "name", value: `go \${spring}`, line: 5},
+			{key: "greeting", value: "hello\n\"world\" \\${user}", line: 6},
+			{key: "cert", value: "-----BEGIN-----\nabc\n-----END-----", line: 7},
+			{key: "app.name", value: "go-spring", line: 10},
+			{key: "empty", value: "", line: 11},
+		})
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for s, msg := range map[string]string{
+			"KEY":             `invalid dotenv line 1: "KEY"`,
+			"1KEY=a":          `invalid dotenv key at line 1: "1KEY"`,
+			"KEY=\"abc\nxyz":  `unterminated quoted value at dotenv line 1`,
+			"\nKEY='abc' xyz": `unexpected "xyz" after quoted value at dotenv line 2`,
+			"export\nKEY=abc": `invalid dotenv line 1: "export"`,
+		} {
+			_, err := parseDotenv([]byte(s))
+			assert.Equal(t, err.Error(), msg)
+		}
+	})
+}
+
+func TestDotenvPropertySource(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "dotenv")
+	assert.Equal(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, ".env")
+	p := NewPropertySource("dotenv:" + filename)
+	assert.Equal(t, p.Name(), "dotenv")
+
+	// 文件不存在时返回空的属性列表
+	assert.Equal(t, len(p.Load("", nil).GetProperties()), 0)
+
+	writeConfigTree(t, dir, map[string]string{
+		".env":                   "DB_PASSWORD=local\nGREETING='${name}'\nNAME=dev\n",
+		"application.properties": "db.password=file\ndb.user=root\n",
+	})
+	assert.Equal(t, p.(*dotenvPropertySource).snapshot.changed(), true)
+
+	result := p.Load("", nil)
+	assert.Equal(t, result.GetProperty("db.password"), "local")
+	assert.Equal(t, result.GetProperty("greeting"), "${name}")
+
+	origin, _ := result.GetPropertyOrigin("db.password")
+	assert.Equal(t, origin.String(), filename+":1")
+
+	// .env 文件覆盖同一配置层中的普通配置文件
+	app := newApplication(&defaultApplicationContext{
+		SpringContext: SpringCore.NewDefaultSpringContext(),
+	}, "dotenv:"+filename, dir)
+	sources := app.loadPropertySources()
+	config := app.loadProfileConfig(sources, "", nil)
+	assert.Equal(t, config.GetProperty("db.password"), "local")
+	assert.Equal(t, config.GetProperty("db.user"), "root")
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringBoot
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-spring/go-spring-parent/spring-logger"
+	"github.com/go-spring/go-spring-parent/spring-utils"
+	"github.com/go-spring/go-spring/spring-core"
+)
+
+const (
+	SpringConfigImport   = "spring.config.import" // 导入其他配置文件
+	optionalImportPrefix = "optional:"            // 可选的导入，文件不存在时忽略
+)
+
+// loadConfigFile 加载配置文件中生效的文档以及通过 spring.config.import 导入的配置
+// 文件。导入路径使用逗号分隔或者列表的形式，相对路径相对于导入它的配置文件所在的目录，
+// 带有 optional: 前缀的导入在文件不存在时被忽略。导入的配置文件先于导入它的文档加载，
+// 所以文档的属性值覆盖导入的属性值，后导入的文件覆盖先导入的文件。没有声明运行环境的
+// 文档中的导入总是被处理，导入的文件使用相同的规则判断其中的文档是否生效。snapshot
+// 不为 nil 时记录所有导入的文件，包括不存在的可选文件。
+func loadConfigFile(filename string, reader ConfigReader, activate documentActivator, out SpringCore.Properties, snapshot *fileSnapshot) {
+	importConfigFile(filepath.Clean(filename), reader, activate, out, snapshot, nil)
+}
+
+// importConfigFile 递归加载配置文件，stack 是正在加载的配置文件，用于检测循环导入。
+func importConfigFile(filename string, reader ConfigReader, activate documentActivator,
+	out SpringCore.Properties, snapshot *fileSnapshot, stack []string) {
+
+	stack = append(append([]string{}, stack...), filename)
+
+	buffer, err := ioutil.ReadFile(filename)
+	SpringUtils.Panic(err).When(err != nil)
+
+	for _, doc := range readConfigDocuments(reader, buffer, filename) {
+
+		active := activate(doc.profiles)
+		if !active && len(doc.profiles) > 0 {
+			continue
+		}
+
+		for _, location := range propertyList(doc.properties.GetProperty(SpringConfigImport)) {
+
+			optional := strings.HasPrefix(location, optionalImportPrefix)
+			path := strings.TrimPrefix(location, optionalImportPrefix)
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(filepath.Dir(filename), path)
+			}
+
+			for _, s := range stack {
+				if s == path {
+					cycle := strings.Join(append(stack, path), " -> ")
+					panic(fmt.Errorf("found config import cycle: %s", cycle))
+				}
+			}
+
+			r, ok := configReaders[filepath.Ext(path)]
+			if !ok {
+				panic(fmt.Errorf("unsupported config import \"%s\" in %s", location, filename))
+			}
+
+			if snapshot != nil {
+				snapshot.add(path)
+			}
+
+			if _, err = os.Stat(path); err != nil {
+				if optional && os.IsNotExist(err) {
+					continue
+				}
+				panic(fmt.Errorf("can't import config \"%s\" in %s: %v", location, filename, err))
+			}
+
+			SpringLogger.Info("import properties from file ", path)
+			importConfigFile(path, r, activate, out, snapshot, stack)
+		}
+
+		if active {
+			copyDocument(doc, out)
+		}
+	}
+}
+
+// propertyList 将逗号分隔的字符串或者列表形式的属性值转换为字符串列表，忽略空白项。
+func propertyList(v interface{}) []string {
+
+	var items []string
+	switch s := v.(type) {
+	case nil:
+		return nil
+	case string:
+		items = strings.Split(s, ",")
+	case []string:
+		items = s
+	case []interface{}:
+		for _, item := range s {
+			items = append(items, fmt.Sprint(item))
+		}
+	default:
+		items = []string{fmt.Sprint(s)}
+	}
+
+	var result []string
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,161 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringBoot
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-spring/go-spring/spring-core"
+	"github.com/magiconair/properties/assert"
+)
+
+func TestConfigImport(t *testing.T) {
+
+	t.Run("import", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "config-import")
+		assert.Equal(t, err, nil)
+		defer os.RemoveAll(dir)
+
+		writeConfigTree(t, dir, map[string]string{
+			"config/application.properties": "spring.config.import=../shared/db.yaml,optional:missing.properties,../shared/logging.properties\n" +
+				"db.url=mysql://app\n",
+			"shared/db.yaml":            "spring.config.import: [common.toml]\ndb:\n  url: mysql://shared\n  pool: 10\n",
+			"shared/common.toml":        "region = \"cn\"\nlevel = \"info\"\n",
+			"shared/logging.properties": "level=debug\n",
+		})
+
+		p := NewDefaultPropertySource(filepath.Join(dir, "config"))
+		result := p.Load("", nil)
+
+		// 当前文件覆盖导入的文件，后导入的文件覆盖先导入的文件
+		assert.Equal(t, result.GetProperty("db.url"), "mysql://app")
+		assert.Equal(t, result.GetProperty("db.pool"), 10)
+		assert.Equal(t, result.GetProperty("region"), "cn")
+		assert.Equal(t, result.GetProperty("level"), "debug")
+
+		origin, _ := result.GetPropertyOrigin("db.pool")
+		assert.Equal(t, origin.String(), filepath.Join(dir, "shared", "db.yaml")+":4")
+
+		// 可选的导入文件被创建时能够检测到变化
+		assert.Equal(t, p.snapshot.changed(), false)
+		writeConfigTree(t, dir, map[string]string{"config/missing.properties": "level=warn\n"})
+		assert.Equal(t, p.snapshot.changed(), true)
+		assert.Equal(t, p.Load("", nil).GetProperty("level"), "debug")
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "config-import")
+		assert.Equal(t, err, nil)
+		defer os.RemoveAll(dir)
+
+		writeConfigTree(t, dir, map[string]string{
+			"application.properties": "spring.config.import=a.properties\n",
+			"a.properties":           "spring.config.import=b.properties\n",
+			"b.properties":           "spring.config.import=a.properties\n",
+		})
+
+		assert.Panic(t, func() {
+			NewDefaultPropertySource(dir).Load("", nil)
+		}, "found config import cycle: .*a.properties -> .*b.properties -> .*a.properties")
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "config-import")
+		assert.Equal(t, err, nil)
+		defer os.RemoveAll(dir)
+
+		writeConfigTree(t, dir, map[string]string{
+			"application.properties": "spring.config.import=db.properties\n",
+		})
+
+		assert.Panic(t, func() {
+			NewDefaultPropertySource(dir).Load("", nil)
+		}, "can't import config \"db.properties\" in .*application.properties")
+	})
+}
+
+func TestProfilesInclude(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "profiles-include")
+	assert.Equal(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	writeConfigTree(t, dir, map[string]string{
+		"application.properties":       "spring.profile=prod\nspring.profiles.include=db,mq\nname=app\nlevel=info\n",
+		"application-prod.properties":  "name=prod\n",
+		"application-db.properties":    "spring.profiles.include=mysql\ndb.url=mysql://db\nlevel=db\n",
+		"application-mysql.properties": "db.url=mysql://mysql\ndb.pool=10\n",
+		"application-mq.properties":    "spring.profiles.include=db\nlevel=mq\n",
+	})
+
+	app := newApplication(&defaultApplicationContext{
+		SpringContext: SpringCore.NewDefaultSpringContext(),
+	}, dir)
+	app.appCtx.SetProperty("application-event.collection", "[]?")
+	app.appCtx.SetProperty("command-line-runner.collection", "[]?")
+	app.Start()
+	defer app.ShutDown()
+
+	assert.Equal(t, app.appCtx.GetProfiles(), []string{"prod", "db", "mysql", "mq"})
+	assert.Equal(t, app.appCtx.GetProperty("name"), "prod")
+
+	// 被包含的运行环境覆盖它包含的运行环境，后包含的覆盖先包含的
+	assert.Equal(t, app.appCtx.GetProperty("db.url"), "mysql://db")
+	assert.Equal(t, app.appCtx.GetProperty("db.pool"), "10")
+	assert.Equal(t, app.appCtx.GetProperty("level"), "mq")
+
+	origin, _ := app.appCtx.GetPropertyOrigin("db.pool")
+	assert.Equal(t, origin.Layer, "profile-config")
+}
+
+func TestMultipleProfiles(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "multiple-profiles")
+	assert.Equal(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	writeConfigTree(t, dir, map[string]string{
+		"application.properties":        "spring.profile=production, metrics\nspring.profiles.group.production=proddb,prodmq\nname=app\n",
+		"application-production.yaml":   "name: production\nlevel: warn\n",
+		"application-proddb.properties": "name=proddb\ndb.url=mysql://prod\n",
+		"application-metrics.yaml":      "name: metrics\nmetrics.enabled: true\n",
+		"application.yaml":              "---\nspring.config.activate.on-profile: prodmq | eu\nmq.url: amqp://prod\n",
+	})
+
+	app := newApplication(&defaultApplicationContext{
+		SpringContext: SpringCore.NewDefaultSpringContext(),
+	}, dir)
+	app.appCtx.SetProperty("application-event.collection", "[]?")
+	app.appCtx.SetProperty("command-line-runner.collection", "[]?")
+	app.Start()
+	defer app.ShutDown()
+
+	// 分组的成员紧跟在分组之后，按照声明的顺序后面的覆盖前面的
+	assert.Equal(t, app.appCtx.GetProfiles(), []string{"production", "proddb", "prodmq", "metrics"})
+	assert.Equal(t, app.appCtx.GetProfile(), "production,proddb,prodmq,metrics")
+	assert.Equal(t, app.appCtx.GetProperty("name"), "metrics")
+	assert.Equal(t, app.appCtx.GetProperty("level"), "warn")
+	assert.Equal(t, app.appCtx.GetProperty("db.url"), "mysql://prod")
+	assert.Equal(t, app.appCtx.GetProperty("mq.url"), "amqp://prod")
+	assert.Equal(t, app.appCtx.GetBoolProperty("metrics.enabled"), true)
+
+	assert.Equal(t, SpringCore.NewProfileCondition("prodmq & !eu").Matches(app.appCtx), true)
+	assert.Equal(t, SpringCore.NewProfileCondition("dev | test").Matches(app.appCtx), false)
+}
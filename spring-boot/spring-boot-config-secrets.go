@@ -0,0 +1,147 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringBoot
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-spring/go-spring-parent/spring-logger"
+	"github.com/go-spring/go-spring-parent/spring-utils"
+	"github.com/go-spring/go-spring/spring-core"
+)
+
+// 同一配置层中属性源的优先级，密钥目录覆盖 .env 文件，.env 文件覆盖普通的配置文件。
+const (
+	dotenvPropertySourcePriority  = 100
+	secretsPropertySourcePriority = 200
+)
+
+func init() {
+	RegisterPropertySourceFactory("secrets", func(location string) PropertySource {
+		dir, options := parseLocationOptions(location)
+		p := NewSecretsPropertySource(dir)
+		for k, v := range options {
+			switch k {
+			case "prefix":
+				p.prefix = strings.Trim(v, ".")
+			case "trim":
+				trim, err := strconv.ParseBool(v)
+				if err != nil {
+					panic(fmt.Errorf("invalid secrets option trim=\"%s\"", v))
+				}
+				p.trim = trim
+			default:
+				panic(fmt.Errorf("unsupported secrets option \"%s\"", k))
+			}
+		}
+		return p
+	})
+}
+
+// parseLocationOptions 解析配置路径中 ? 之后的选项，如 /run/secrets?prefix=db&trim=false。
+func parseLocationOptions(location string) (string, map[string]string) {
+
+	i := strings.Index(location, "?")
+	if i < 0 {
+		return location, nil
+	}
+
+	values, err := url.ParseQuery(location[i+1:])
+	if err != nil {
+		panic(fmt.Errorf("invalid config location \"%s\": %v", location, err))
+	}
+
+	options := make(map[string]string)
+	for k, v := range values {
+		options[k] = v[len(v)-1]
+	}
+	return location[:i], options
+}
+
+// secretsPropertySource 基于密钥目录的属性源，用于读取 Docker 和 k8s 以文件形式
+// 提供的密钥，如 /run/secrets/db_password。每个文件对应一个属性，文件名和目录名
+// 按照 SpringCore.EnvToPropertyKey 的规则转换为属性名，子目录中文件的属性名以 . 连接
+// 目录名，文件内容作为属性值。属性名可以添加前缀，属性值默认去掉首尾的空白字符，
+// 比如文件末尾的换行符。以 . 开头的文件和目录被忽略。密钥的属性值被标记为敏感的，
+// 不会出现在日志和诊断输出中。
+type secretsPropertySource struct {
+	dir      string              // 密钥目录
+	prefix   string              // 属性名的前缀
+	trim     bool                // 是否去掉属性值首尾的空白字符
+	snapshot *configTreeSnapshot // 密钥目录的快照
+}
+
+// NewSecretsPropertySource secretsPropertySource 的构造函数
+func NewSecretsPropertySource(dir string) *secretsPropertySource {
+	return &secretsPropertySource{dir: dir, trim: true, snapshot: newConfigTreeSnapshot(dir)}
+}
+
+// Name 返回属性源的名称
+func (p *secretsPropertySource) Name() string {
+	return "secrets"
+}
+
+// Priority 返回属性源的优先级，密钥覆盖同一配置层中其他属性源的属性值。
+func (p *secretsPropertySource) Priority() int {
+	return secretsPropertySourcePriority
+}
+
+// Load 加载密钥文件，密钥只属于默认的 profile。
+func (p *secretsPropertySource) Load(profile string, active []string) SpringCore.Properties {
+
+	files := p.snapshot.scan()
+	result := SpringCore.NewDefaultProperties()
+	if profile != "" {
+		return result
+	}
+
+	SpringLogger.Info("load properties from secrets ", p.dir)
+
+	for _, f := range files {
+		buffer, err := ioutil.ReadFile(f.path)
+		SpringUtils.Panic(err).When(err != nil)
+
+		value := string(buffer)
+		if p.trim {
+			value = strings.TrimSpace(value)
+		}
+
+		segments := strings.Split(f.key, ".")
+		for i, segment := range segments {
+			segments[i] = SpringCore.EnvToPropertyKey(segment)
+		}
+
+		key := strings.Join(segments, ".")
+		if p.prefix != "" {
+			key = p.prefix + "." + key
+		}
+
+		origin := SpringCore.PropertyOrigin{Source: f.path, Sensitive: true}
+		result.SetPropertyWithOrigin(key, value, origin)
+	}
+
+	return result
+}
+
+// Watch 轮询密钥目录，..data 符号链接的目标或者目录中的文件发生变化时调用 onChange。
+func (p *secretsPropertySource) Watch(onChange func()) (stop func()) {
+	return pollWatch(fileWatchInterval, p.snapshot.changed, onChange)
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringBoot
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-spring/go-spring/spring-core"
+	"github.com/magiconair/properties/assert"
+)
+
+func TestSecretsPropertySource(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "secrets")
+	assert.Equal(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	writeConfigTree(t, dir, map[string]string{
+		"db_password":  "s3cr3t\n",
+		"API_TOKEN":    "  token  ",
+		"redis/secret": "redis\n",
+		".hidden":      "hidden",
+	})
+
+	t.Run("default", func(t *testing.T) {
+		p := NewPropertySource("secrets:" + dir)
+		assert.Equal(t, p.Name(), "secrets")
+		assert.Equal(t, propertySourcePriority(p), secretsPropertySourcePriority)
+
+		result := p.Load("", nil)
+		assert.Equal(t, result.GetProperties(), map[string]interface{}{
+			"db.password":  "s3cr3t",
+			"api.token":    "token",
+			"redis.secret": "redis",
+		})
+
+		origin, _ := result.GetPropertyOrigin("db.password")
+		assert.Equal(t, origin.Source, filepath.Join(dir, "db_password"))
+		assert.Equal(t, origin.Sensitive, true)
+		assert.Equal(t, maskProperties(result)["db.password"], SpringCore.MaskedPropertyValue)
+
+		// 密钥只属于默认的 profile
+		assert.Equal(t, len(p.Load("test", nil).GetProperties()), 0)
+	})
+
+	t.Run("options", func(t *testing.T) {
+		p := NewPropertySource("secrets:" + dir + "?prefix=app.secrets.&trim=false")
+		result := p.Load("", nil)
+		assert.Equal(t, result.GetProperty("app.secrets.db.password"), "s3cr3t\n")
+		assert.Equal(t, result.GetProperty("app.secrets.api.token"), "  token  ")
+		assert.Equal(t, result.GetProperty("db.password"), nil)
+
+		assert.Panic(t, func() {
+			NewPropertySource("secrets:" + dir + "?trim=yes")
+		}, `invalid secrets option trim="yes"`)
+
+		assert.Panic(t, func() {
+			NewPropertySource("secrets:" + dir + "?suffix=x")
+		}, `unsupported secrets option "suffix"`)
+	})
+
+	t.Run("watch", func(t *testing.T) {
+		p := NewSecretsPropertySource(dir)
+		p.Load("", nil)
+		assert.Equal(t, p.snapshot.changed(), false)
+
+		writeConfigTree(t, dir, map[string]string{"db_user": "root"})
+		assert.Equal(t, p.snapshot.changed(), true)
+		assert.Equal(t, p.Load("", nil).GetProperty("db.user"), "root")
+	})
+}
@@ -0,0 +1,317 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringBoot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-spring/go-spring-parent/spring-logger"
+	"github.com/go-spring/go-spring-parent/spring-utils"
+	"github.com/go-spring/go-spring/spring-core"
+)
+
+const (
+	ConfigServerCacheDir     = "cache-dir"     // 配置服务器属性源的本地缓存目录参数
+	ConfigServerPollInterval = "poll-interval" // 配置服务器属性源的轮询间隔参数
+)
+
+func init() {
+
+	// 配置路径形如 http://config-server:8888/my-app?cache-dir=/tmp/config&poll-interval=30s，
+	// cache-dir 和 poll-interval 是属性源的参数，不会发送给配置服务器。
+	factory := func(scheme string) PropertySourceFactory {
+		return func(location string) PropertySource {
+			return parseConfigServerLocation(scheme + ":" + location)
+		}
+	}
+
+	RegisterPropertySourceFactory("http", factory("http"))
+	RegisterPropertySourceFactory("https", factory("https"))
+}
+
+// configServerDocument 配置服务器返回的文档，propertySources 中排在前面的属性值优先。
+type configServerDocument struct {
+	Name            string   `json:"name"`
+	Profiles        []string `json:"profiles"`
+	PropertySources []struct {
+		Name   string                 `json:"name"`
+		Source map[string]interface{} `json:"source"`
+	} `json:"propertySources"`
+}
+
+// configServerResponse 已经获取到的文档内容
+type configServerResponse struct {
+	etag string
+	body []byte
+}
+
+// ConfigServerPropertySource 基于 HTTP 配置服务器的属性源，从 {url}/application
+// 和 {url}/application-{profile} 获取 JSON 格式的文档，支持 ETag 缓存协商。配置
+// 服务器不可用时可以使用本地缓存的文档，还可以通过轮询监听文档的变化。
+type ConfigServerPropertySource struct {
+	url          string        // 配置服务器地址
+	cacheDir     string        // 本地缓存目录，为空时不使用本地缓存
+	pollInterval time.Duration // 轮询间隔，为 0 时不轮询
+	client       *http.Client
+
+	mutex     sync.Mutex
+	responses map[string]*configServerResponse // 文档名称 -> 文档内容
+}
+
+// NewConfigServerPropertySource ConfigServerPropertySource 的构造函数
+func NewConfigServerPropertySource(url string) *ConfigServerPropertySource {
+	return &ConfigServerPropertySource{
+		url:       strings.TrimSuffix(url, "/"),
+		client:    &http.Client{Timeout: 10 * time.Second},
+		responses: make(map[string]*configServerResponse),
+	}
+}
+
+// parseConfigServerLocation 解析配置路径中的属性源参数，然后创建属性源
+func parseConfigServerLocation(location string) *ConfigServerPropertySource {
+
+	u, err := url.Parse(location)
+	SpringUtils.Panic(err).When(err != nil)
+
+	query := u.Query()
+	cacheDir := query.Get(ConfigServerCacheDir)
+	pollInterval := query.Get(ConfigServerPollInterval)
+	query.Del(ConfigServerCacheDir)
+	query.Del(ConfigServerPollInterval)
+	u.RawQuery = query.Encode()
+
+	p := NewConfigServerPropertySource(u.String())
+	p.CacheDir(cacheDir)
+
+	if pollInterval != "" {
+		d, err := time.ParseDuration(pollInterval)
+		SpringUtils.Panic(err).When(err != nil)
+		p.PollInterval(d)
+	}
+	return p
+}
+
+// CacheDir 设置本地缓存目录
+func (p *ConfigServerPropertySource) CacheDir(dir string) *ConfigServerPropertySource {
+	p.cacheDir = dir
+	return p
+}
+
+// PollInterval 设置轮询间隔
+func (p *ConfigServerPropertySource) PollInterval(d time.Duration) *ConfigServerPropertySource {
+	p.pollInterval = d
+	return p
+}
+
+// Name 返回属性源的名称
+func (p *ConfigServerPropertySource) Name() string {
+	return p.url
+}
+
+// documentURL 返回文档的地址，保留配置服务器地址中的查询参数
+func (p *ConfigServerPropertySource) documentURL(name string) string {
+	if i := strings.Index(p.url, "?"); i >= 0 {
+		return p.url[:i] + "/" + name + p.url[i:]
+	}
+	return p.url + "/" + name
+}
+
+// cacheFile 返回文档的本地缓存文件
+func (p *ConfigServerPropertySource) cacheFile(name string) string {
+	return filepath.Join(p.cacheDir, name+".json")
+}
+
+// fetch 从配置服务器获取文档，返回文档内容以及文档是否发生了变化，文档不存在
+// 时返回空的文档内容。
+func (p *ConfigServerPropertySource) fetch(name string) ([]byte, bool, error) {
+
+	req, err := http.NewRequest(http.MethodGet, p.documentURL(name), nil)
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	p.mutex.Lock()
+	last, ok := p.responses[name]
+	p.mutex.Unlock()
+
+	if ok && last.etag != "" {
+		req.Header.Set("If-None-Match", last.etag)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		if ok {
+			return last.body, false, nil
+		}
+		return nil, false, fmt.Errorf("%s returns 304 without previous response", req.URL)
+	case http.StatusNotFound:
+		body = nil
+	case http.StatusOK:
+		if _, err = parseConfigServerDocument(body); err != nil {
+			return nil, false, fmt.Errorf("%s returns invalid document: %v", req.URL, err)
+		}
+	default:
+		return nil, false, fmt.Errorf("%s returns status %d", req.URL, resp.StatusCode)
+	}
+
+	p.mutex.Lock()
+	p.responses[name] = &configServerResponse{etag: resp.Header.Get("ETag"), body: body}
+	p.mutex.Unlock()
+
+	// 不存在的文档也写入缓存，这样配置服务器不可用时能够区分文档不存在和没有缓存
+	if p.cacheDir != "" {
+		p.writeCache(name, body)
+	}
+
+	changed := !ok || !bytes.Equal(last.body, body)
+	return body, changed, nil
+}
+
+// writeCache 将文档写入本地缓存，写入失败只打印警告
+func (p *ConfigServerPropertySource) writeCache(name string, body []byte) {
+	if err := os.MkdirAll(p.cacheDir, os.ModePerm); err != nil {
+		SpringLogger.Warnf("write config server cache error: %v", err)
+		return
+	}
+	if err := ioutil.WriteFile(p.cacheFile(name), body, 0644); err != nil {
+		SpringLogger.Warnf("write config server cache error: %v", err)
+	}
+}
+
+// Load 加载属性文件，profile 配置文件剖面。配置服务器不可用时使用本地缓存，
+// 没有本地缓存时 panic。
+func (p *ConfigServerPropertySource) Load(profile string, active []string) SpringCore.Properties {
+
+	name := "application"
+	if profile != "" {
+		name += "-" + profile
+	}
+
+	source := p.documentURL(name)
+	body, _, err := p.fetch(name)
+
+	if err != nil {
+		if p.cacheDir == "" {
+			panic(err)
+		}
+
+		SpringLogger.Warnf("config server unavailable, use local cache: %v", err)
+
+		source = p.cacheFile(name)
+		cache, e := ioutil.ReadFile(source)
+		if e != nil {
+			panic(fmt.Errorf("read config server cache error: %v, fetch error: %v", e, err))
+		}
+		body = cache
+
+		// 记录使用缓存的文档，这样配置服务器恢复之后能够轮询到文档的变化
+		p.mutex.Lock()
+		p.responses[name] = &configServerResponse{body: body}
+		p.mutex.Unlock()
+	}
+
+	SpringLogger.Info("load properties from config server ", source)
+
+	result := SpringCore.NewDefaultProperties()
+	if len(body) == 0 {
+		return result
+	}
+
+	doc, err := parseConfigServerDocument(body)
+	SpringUtils.Panic(err).When(err != nil)
+
+	// 排在前面的属性值优先，所以倒序写入
+	for i := len(doc.PropertySources) - 1; i >= 0; i-- {
+		ps := doc.PropertySources[i]
+		origin := SpringCore.PropertyOrigin{Source: source + "#" + ps.Name}
+		flattenProperties("", ps.Source, func(k string, v interface{}) {
+			result.SetPropertyWithOrigin(k, v, origin)
+		})
+	}
+	return result
+}
+
+// Watch 开始轮询已经加载过的文档，文档发生变化时调用 onChange。
+func (p *ConfigServerPropertySource) Watch(onChange func()) (stop func()) {
+	return pollWatch(p.pollInterval, p.poll, onChange)
+}
+
+// poll 重新获取已经加载过的文档，返回是否有文档发生了变化。
+func (p *ConfigServerPropertySource) poll() bool {
+
+	p.mutex.Lock()
+	names := make([]string, 0, len(p.responses))
+	for name := range p.responses {
+		names = append(names, name)
+	}
+	p.mutex.Unlock()
+
+	changed := false
+	for _, name := range names {
+		if _, ok, err := p.fetch(name); err != nil {
+			SpringLogger.Warnf("poll config server error: %v", err)
+		} else if ok {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// parseConfigServerDocument 解析配置服务器返回的文档
+func parseConfigServerDocument(body []byte) (*configServerDocument, error) {
+	doc := new(configServerDocument)
+	if err := json.Unmarshal(body, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// flattenProperties 将嵌套的属性值展开为以 . 分隔的属性名
+func flattenProperties(prefix string, m map[string]interface{}, fn func(k string, v interface{})) {
+	for k, v := range m {
+		if prefix != "" {
+			k = prefix + "." + k
+		}
+		if sub, ok := v.(map[string]interface{}); ok {
+			flattenProperties(k, sub, fn)
+		} else {
+			fn(k, v)
+		}
+	}
+}
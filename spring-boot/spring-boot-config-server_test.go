@@ -0,0 +1,233 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringBoot
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-spring/go-spring/spring-core"
+	"github.com/magiconair/properties/assert"
+)
+
+// configServer 模拟的配置服务器
+type configServer struct {
+	mutex       sync.Mutex
+	documents   map[string]string // 路径 -> 文档
+	versions    map[string]int    // 路径 -> 版本号
+	notModified int               // 返回 304 的次数
+	unavailable bool              // 是否返回 503
+}
+
+func newConfigServer() *configServer {
+	return &configServer{
+		documents: make(map[string]string),
+		versions:  make(map[string]int),
+	}
+}
+
+func (s *configServer) set(path string, doc string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.documents[path] = doc
+	s.versions[path]++
+}
+
+func (s *configServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.unavailable {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	doc, ok := s.documents[r.URL.Path]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	etag := fmt.Sprintf(`"v%d"`, s.versions[r.URL.Path])
+	if r.Header.Get("If-None-Match") == etag {
+		s.notModified++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("ETag", etag)
+	_, _ = w.Write([]byte(doc))
+}
+
+const configServerDoc = `{
+  "name": "my-app",
+  "propertySources": [
+    {"name": "my-app.yaml", "source": {"server.port": 8081, "db": {"url": "mysql://db"}}},
+    {"name": "application.yaml", "source": {"server.port": 8080, "server.host": "localhost"}}
+  ]
+}`
+
+func TestConfigServerPropertySource(t *testing.T) {
+
+	t.Run("load", func(t *testing.T) {
+		s := newConfigServer()
+		s.set("/my-app/application", configServerDoc)
+		server := httptest.NewServer(s)
+		defer server.Close()
+
+		p := NewConfigServerPropertySource(server.URL + "/my-app")
+		result := p.Load("", nil)
+		assert.Equal(t, result.GetProperty("server.port"), float64(8081))
+		assert.Equal(t, result.GetProperty("server.host"), "localhost")
+		assert.Equal(t, result.GetProperty("db.url"), "mysql://db")
+
+		origin, _ := result.GetPropertyOrigin("server.port")
+		assert.Equal(t, origin.Source, server.URL+"/my-app/application#my-app.yaml")
+
+		// 不存在的文档返回空的属性列表
+		assert.Equal(t, len(p.Load("test", nil).GetProperties()), 0)
+
+		// 再次加载时使用 ETag 协商
+		result = p.Load("", nil)
+		assert.Equal(t, result.GetProperty("server.port"), float64(8081))
+		assert.Equal(t, s.notModified, 1)
+	})
+
+	t.Run("cache", func(t *testing.T) {
+		cacheDir, err := ioutil.TempDir("", "config-server")
+		assert.Equal(t, err, nil)
+		defer os.RemoveAll(cacheDir)
+
+		s := newConfigServer()
+		s.set("/my-app/application", configServerDoc)
+		server := httptest.NewServer(s)
+
+		location := server.URL + "/my-app?cache-dir=" + cacheDir
+		p := NewPropertySource(location).(*ConfigServerPropertySource)
+		assert.Equal(t, p.Name(), server.URL+"/my-app")
+		p.Load("", nil)
+		p.Load("test", nil)
+		server.Close()
+
+		p = NewPropertySource(location).(*ConfigServerPropertySource)
+		result := p.Load("", nil)
+		assert.Equal(t, result.GetProperty("server.port"), float64(8081))
+		origin, _ := result.GetPropertyOrigin("server.port")
+		assert.Equal(t, origin.Source, filepath.Join(cacheDir, "application.json")+"#my-app.yaml")
+		assert.Equal(t, len(p.Load("test", nil).GetProperties()), 0)
+
+		p = NewConfigServerPropertySource(server.URL + "/my-app")
+		assert.Panic(t, func() { p.Load("", nil) }, "connection refused")
+
+		p = NewPropertySource(location).(*ConfigServerPropertySource)
+		assert.Panic(t, func() {
+			p.Load("dev", nil)
+		}, "read config server cache error: .*, fetch error: .*connection refused")
+	})
+
+	t.Run("recover", func(t *testing.T) {
+		cacheDir, err := ioutil.TempDir("", "config-server")
+		assert.Equal(t, err, nil)
+		defer os.RemoveAll(cacheDir)
+
+		s := newConfigServer()
+		s.set("/my-app/application", configServerDoc)
+		server := httptest.NewServer(s)
+		defer server.Close()
+
+		location := server.URL + "/my-app?cache-dir=" + cacheDir
+		NewPropertySource(location).Load("", nil)
+
+		s.mutex.Lock()
+		s.unavailable = true
+		s.mutex.Unlock()
+
+		p := NewPropertySource(location).(*ConfigServerPropertySource)
+		assert.Equal(t, p.Load("", nil).GetProperty("server.port"), float64(8081))
+		assert.Equal(t, p.poll(), false)
+
+		s.mutex.Lock()
+		s.unavailable = false
+		s.mutex.Unlock()
+
+		// 配置服务器恢复之后内容和缓存一致时没有变化
+		assert.Equal(t, p.poll(), false)
+
+		s.set("/my-app/application", `{"propertySources":[{"name":"a","source":{"server.port":9090}}]}`)
+		assert.Equal(t, p.poll(), true)
+	})
+
+	t.Run("poll", func(t *testing.T) {
+		s := newConfigServer()
+		s.set("/my-app/application", configServerDoc)
+		server := httptest.NewServer(s)
+		defer server.Close()
+
+		p := NewConfigServerPropertySource(server.URL + "/my-app").PollInterval(10 * time.Millisecond)
+		p.Load("", nil)
+
+		changed := make(chan struct{}, 1)
+		stop := p.Watch(func() {
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		})
+		defer stop()
+
+		s.set("/my-app/application", `{"propertySources":[{"name":"a","source":{"server.port":9090}}]}`)
+
+		select {
+		case <-changed:
+		case <-time.After(time.Second):
+			t.Fatal("config server change not detected")
+		}
+
+		assert.Equal(t, p.Load("", nil).GetProperty("server.port"), float64(9090))
+	})
+
+	t.Run("application", func(t *testing.T) {
+		os.Clearenv()
+		s := newConfigServer()
+		s.set("/my-app/application", configServerDoc)
+		s.set("/my-app/application-test", `{"propertySources":[{"name":"test","source":{"server.host":"test-host"}}]}`)
+		server := httptest.NewServer(s)
+		defer server.Close()
+
+		app := newApplication(&defaultApplicationContext{
+			SpringContext: SpringCore.NewDefaultSpringContext(),
+		}, "testdata/config/", server.URL+"/my-app?poll-interval=1h")
+		app.appCtx.SetProperty("application-event.collection", "[]?")
+		app.appCtx.SetProperty("command-line-runner.collection", "[]?")
+		app.appCtx.SetProperty(SpringConfigReloadEnabled, true)
+		app.Start()
+		defer app.ShutDown()
+
+		assert.Equal(t, app.appCtx.GetIntProperty("server.port"), int64(8081))
+		assert.Equal(t, app.appCtx.GetProperty("server.host"), "test-host")
+
+		// 文件属性源、配置服务器属性源和 SIGHUP 信号
+		assert.Equal(t, len(app.stopWatch), 3)
+	})
+}
@@ -0,0 +1,235 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringBoot
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-spring/go-spring-parent/spring-logger"
+	"github.com/go-spring/go-spring-parent/spring-utils"
+	"github.com/go-spring/go-spring/spring-core"
+)
+
+// configTreeDataDir k8s 挂载 ConfigMap 和 Secret 时指向当前数据目录的符号链接
+const configTreeDataDir = "..data"
+
+func init() {
+	RegisterPropertySourceFactory("configtree", func(location string) PropertySource {
+		return NewConfigTreePropertySource(location)
+	})
+}
+
+// configTreeFile 目录中的配置文件
+type configTreeFile struct {
+	path string // 文件路径
+	key  string // 相对路径转换成的属性名，如 db/password 转换成 db.password
+	ext  string // 文件的扩展名
+}
+
+// configTreePropertySource 基于目录的属性源，用于读取 k8s 以目录形式挂载的
+// ConfigMap 和 Secret。扩展名是已注册的配置格式的文件作为一个完整的配置文档，
+// 其中 application-{profile}.ext 只在对应的 profile 下加载，其他文件的文件名
+// 作为属性名，文件内容作为属性值，子目录中文件的属性名以 . 连接目录名。以 .
+// 开头的文件和目录被忽略，比如 k8s 用于原子更新的 ..data 目录。
+type configTreePropertySource struct {
+	dir      string              // 挂载目录
+	snapshot *configTreeSnapshot // 挂载目录的快照
+}
+
+// NewConfigTreePropertySource configTreePropertySource 的构造函数
+func NewConfigTreePropertySource(dir string) *configTreePropertySource {
+	return &configTreePropertySource{dir: dir, snapshot: newConfigTreeSnapshot(dir)}
+}
+
+// Name 返回属性源的名称
+func (p *configTreePropertySource) Name() string {
+	return "configtree"
+}
+
+// Load 加载属性文件，profile 配置文件剖面。
+func (p *configTreePropertySource) Load(profile string, active []string) SpringCore.Properties {
+
+	files := p.snapshot.scan()
+	result := SpringCore.NewDefaultProperties()
+
+	// 首先加载配置文档
+	for _, f := range files {
+		reader, ok := configReaders[f.ext]
+		if !ok {
+			continue
+		}
+
+		activate := configTreeActivator(f, profile, active)
+		if activate == nil {
+			continue
+		}
+
+		SpringLogger.Info("load properties from config tree ", f.path)
+		loadConfigFile(f.path, reader, activate, result, nil)
+	}
+
+	// 然后加载单个属性，单个属性只属于默认的 profile
+	if profile == "" {
+		for _, f := range files {
+			if _, ok := configReaders[f.ext]; ok {
+				continue
+			}
+
+			buffer, err := ioutil.ReadFile(f.path)
+			SpringUtils.Panic(err).When(err != nil)
+
+			origin := SpringCore.PropertyOrigin{Source: f.path}
+			result.SetPropertyWithOrigin(f.key, string(buffer), origin)
+		}
+	}
+
+	return result
+}
+
+// Watch 轮询挂载目录，..data 符号链接的目标或者普通目录中的文件发生变化时调用 onChange。
+func (p *configTreePropertySource) Watch(onChange func()) (stop func()) {
+	return pollWatch(fileWatchInterval, p.snapshot.changed, onChange)
+}
+
+// configTreeActivator 返回配置文档在 profile 运行环境下的文档判断函数，不需要加载
+// 时返回 nil。application-{profile}.ext 是运行环境专属的配置文档，其他配置文档和
+// application.ext 的处理方式相同。
+func configTreeActivator(f configTreeFile, profile string, active []string) documentActivator {
+	name := strings.TrimSuffix(filepath.Base(f.path), f.ext)
+	if strings.HasPrefix(name, "application-") {
+		if profile == "" || name != "application-"+profile {
+			return nil
+		}
+		return activateFor(profile, active, true)
+	}
+	return activateFor(profile, active, false)
+}
+
+// configTreeSnapshot 挂载目录的快照，用于检测目录的变化。
+type configTreeSnapshot struct {
+	dir   string // 挂载目录
+	mutex sync.Mutex
+	state string // 目录的状态，见 configTreeState
+}
+
+// newConfigTreeSnapshot configTreeSnapshot 的构造函数
+func newConfigTreeSnapshot(dir string) *configTreeSnapshot {
+	return &configTreeSnapshot{dir: dir}
+}
+
+// scan 获取目录中的配置文件，同时记录目录的当前状态。
+func (s *configTreeSnapshot) scan() []configTreeFile {
+
+	files, err := scanConfigTree(s.dir)
+	SpringUtils.Panic(err).When(err != nil)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.state = configTreeState(s.dir, files)
+	return files
+}
+
+// changed 重新获取目录的状态，返回目录是否发生了变化
+func (s *configTreeSnapshot) changed() bool {
+
+	files, err := scanConfigTree(s.dir)
+	if err != nil {
+		SpringLogger.Warnf("scan directory %s error: %v", s.dir, err)
+		return false
+	}
+
+	state := configTreeState(s.dir, files)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if state == s.state {
+		return false
+	}
+	s.state = state
+	return true
+}
+
+// configTreeState 返回挂载目录的状态。k8s 通过切换 ..data 符号链接原子地更新
+// 挂载的内容，所以只需要比较符号链接的目标，普通目录则比较所有文件的状态。
+func configTreeState(dir string, files []configTreeFile) string {
+
+	if target, err := os.Readlink(filepath.Join(dir, configTreeDataDir)); err == nil {
+		return target
+	}
+
+	var buf strings.Builder
+	for _, f := range files {
+		state := statFile(f.path)
+		buf.WriteString(fmt.Sprintf("%s:%d:%d;", f.path, state.modTime.UnixNano(), state.size))
+	}
+	return buf.String()
+}
+
+// scanConfigTree 递归获取目录中的配置文件，跟随符号链接，忽略以 . 开头的文件和目录。
+func scanConfigTree(dir string) ([]configTreeFile, error) {
+	var files []configTreeFile
+	err := scanConfigTreeDir(dir, "", &files)
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+	return files, err
+}
+
+func scanConfigTreeDir(dir string, prefix string, files *[]configTreeFile) error {
+
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, info := range infos {
+		name := info.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		// 跟随符号链接获取真实的文件类型
+		if info, err = os.Stat(path); err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if err = scanConfigTreeDir(path, key, files); err != nil {
+				return err
+			}
+			continue
+		}
+
+		*files = append(*files, configTreeFile{
+			path: path,
+			key:  key,
+			ext:  filepath.Ext(name),
+		})
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringBoot
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties/assert"
+)
+
+// writeConfigTree 在目录中写入文件，文件名可以包含子目录
+func writeConfigTree(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		filename := filepath.Join(dir, name)
+		assert.Equal(t, os.MkdirAll(filepath.Dir(filename), os.ModePerm), nil)
+		assert.Equal(t, ioutil.WriteFile(filename, []byte(content), 0644), nil)
+	}
+}
+
+// mountConfigTree 模拟 k8s 挂载 ConfigMap 的方式更新目录：先写入新的数据目录，
+// 然后原子地切换 ..data 符号链接，最后为每个文件创建指向 ..data 的符号链接。
+func mountConfigTree(t *testing.T, dir string, version string, files map[string]string) {
+	dataDir := "..data_" + version
+	writeConfigTree(t, filepath.Join(dir, dataDir), files)
+
+	tmpLink := filepath.Join(dir, "..data_tmp")
+	assert.Equal(t, os.Symlink(dataDir, tmpLink), nil)
+	assert.Equal(t, os.Rename(tmpLink, filepath.Join(dir, configTreeDataDir)), nil)
+
+	for name := range files {
+		link := filepath.Join(dir, name)
+		if _, err := os.Lstat(link); os.IsNotExist(err) {
+			assert.Equal(t, os.Symlink(filepath.Join(configTreeDataDir, name), link), nil)
+		}
+	}
+}
+
+func TestConfigTreePropertySource(t *testing.T) {
+
+	t.Run("plain directory", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "config-tree")
+		assert.Equal(t, err, nil)
+		defer os.RemoveAll(dir)
+
+		writeConfigTree(t, dir, map[string]string{
+			"db.password":             "secret",
+			"db/username":             "root",
+			".hidden":                 "hidden",
+			"application.properties":  "server.port=8080\ndb.username=admin\n",
+			"application-test.yaml":   "server:\n  port: 9090\n",
+			"logging.properties":      "logging.level=info\n",
+			"..2020_01_01/db.timeout": "10s",
+		})
+
+		p := NewPropertySource("configtree:" + dir)
+		assert.Equal(t, p.Name(), "configtree")
+
+		result := p.Load("", nil)
+		assert.Equal(t, result.GetProperty("db.password"), "secret")
+		assert.Equal(t, result.GetProperty("server.port"), "8080")
+		assert.Equal(t, result.GetProperty("logging.level"), "info")
+		assert.Equal(t, result.GetProperty("hidden"), nil)
+		assert.Equal(t, result.GetProperty("db.timeout"), nil)
+
+		// 单个属性文件覆盖配置文档中的属性值
+		assert.Equal(t, result.GetProperty("db.username"), "root")
+		origin, _ := result.GetPropertyOrigin("db.username")
+		assert.Equal(t, origin.Source, filepath.Join(dir, "db", "username"))
+
+		origin, _ = result.GetPropertyOrigin("server.port")
+		assert.Equal(t, origin.String(), filepath.Join(dir, "application.properties")+":1")
+
+		result = p.Load("test", nil)
+		assert.Equal(t, result.GetProperties(), map[string]interface{}{"server.port": 9090})
+	})
+
+	t.Run("watch plain directory", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "config-tree")
+		assert.Equal(t, err, nil)
+		defer os.RemoveAll(dir)
+
+		writeConfigTree(t, dir, map[string]string{"rate.limit": "10"})
+		p := NewConfigTreePropertySource(dir)
+		p.Load("", nil)
+		assert.Equal(t, p.snapshot.changed(), false)
+
+		writeConfigTree(t, dir, map[string]string{"rate.burst": "5"})
+		assert.Equal(t, p.snapshot.changed(), true)
+		assert.Equal(t, p.snapshot.changed(), false)
+	})
+
+	t.Run("watch data symlink", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "config-tree")
+		assert.Equal(t, err, nil)
+		defer os.RemoveAll(dir)
+
+		mountConfigTree(t, dir, "v1", map[string]string{"rate.limit": "10"})
+		p := NewConfigTreePropertySource(dir)
+		assert.Equal(t, p.Load("", nil).GetProperties(), map[string]interface{}{"rate.limit": "10"})
+
+		interval := fileWatchInterval
+		defer func() { fileWatchInterval = interval }()
+		fileWatchInterval = 10 * time.Millisecond
+
+		changed := make(chan struct{}, 1)
+		stop := p.Watch(func() {
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		})
+		defer stop()
+
+		mountConfigTree(t, dir, "v2", map[string]string{"rate.limit": "20"})
+
+		select {
+		case <-changed:
+		case <-time.After(time.Second):
+			t.Fatal("..data symlink swap not detected")
+		}
+
+		assert.Equal(t, p.Load("", nil).GetProperty("rate.limit"), "20")
+	})
+}
@@ -17,74 +17,180 @@
 package SpringBoot
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-spring/go-spring-parent/spring-logger"
 	"github.com/go-spring/go-spring-parent/spring-utils"
+	"github.com/go-spring/go-spring/spring-core"
 	"github.com/spf13/viper"
 )
 
-// propertySource 属性源
-type propertySource interface {
+// PropertySource 属性源，通过 cfgLocation 中形如 scheme:location 的配置路径指定，
+// 如 "k8s:config/config-map.yaml"，没有 scheme 的配置路径使用 file 属性源。
+type PropertySource interface {
 	// Name 返回属性源的名称
 	Name() string
 
-	// Load 加载属性文件，profile 配置文件剖面。
-	Load(profile string) map[string]interface{}
+	// Load 加载属性文件，profile 配置文件剖面，返回的属性值记录了各自的来源。
+	// 属性源自行决定如何处理 profile，比如不区分 profile 的属性源可以在 profile
+	// 不为空时返回空的属性列表。active 是按照声明顺序排列的所有生效的运行环境，
+	// 用于对文档声明的运行环境表达式求值，见 activateFor。
+	Load(profile string, active []string) SpringCore.Properties
+}
+
+// PriorityPropertySource 具有优先级的属性源。同一个配置层中优先级高的属性源覆盖
+// 优先级低的属性源，没有实现该接口的属性源的优先级为 0，优先级相同的属性源按照
+// 配置路径的顺序后者覆盖前者。
+type PriorityPropertySource interface {
+	PropertySource
+
+	// Priority 返回属性源的优先级
+	Priority() int
+}
+
+// WatchablePropertySource 能够监听属性变化的属性源
+type WatchablePropertySource interface {
+	PropertySource
+
+	// Watch 开始监听属性源的变化，发生变化时调用 onChange，返回停止监听的函数。
+	Watch(onChange func()) (stop func())
+}
+
+// PropertySourceFactory 属性源工厂，location 是配置路径去掉 scheme: 之后的部分。
+type PropertySourceFactory func(location string) PropertySource
+
+// propertySourceFactories 属性源工厂集合，key 是 scheme。
+var propertySourceFactories = map[string]PropertySourceFactory{
+	"file": func(location string) PropertySource {
+		return NewDefaultPropertySource(location)
+	},
+	"k8s": func(location string) PropertySource {
+		return NewConfigMapPropertySource(location)
+	},
+}
+
+// RegisterPropertySourceFactory 注册属性源工厂，重复注册会 panic。
+func RegisterPropertySourceFactory(scheme string, factory PropertySourceFactory) {
+	if scheme == "" || strings.Contains(scheme, ":") {
+		panic(fmt.Errorf("invalid property source scheme \"%s\"", scheme))
+	}
+	if _, ok := propertySourceFactories[scheme]; ok {
+		panic(fmt.Errorf("duplicate registration, property source: \"%s\"", scheme))
+	}
+	propertySourceFactories[scheme] = factory
+}
+
+// NewPropertySource 根据配置路径创建属性源，未注册的 scheme 会 panic。未注册的单个
+// 字符的前缀是 Windows 的盘符，如 C:\cfg，此时使用文件属性源。
+func NewPropertySource(configLocation string) PropertySource {
+
+	scheme, location := "file", configLocation
+	if i := strings.Index(configLocation, ":"); i > 0 {
+		if _, ok := propertySourceFactories[configLocation[:i]]; ok || i > 1 {
+			scheme, location = configLocation[:i], configLocation[i+1:]
+		}
+	}
+
+	factory, ok := propertySourceFactories[scheme]
+	if !ok {
+		panic(fmt.Errorf("unsupported property source scheme \"%s\"", scheme))
+	}
+	return factory(location)
+}
+
+// propertySourcePriority 返回属性源的优先级
+func propertySourcePriority(source PropertySource) int {
+	if p, ok := source.(PriorityPropertySource); ok {
+		return p.Priority()
+	}
+	return 0
+}
+
+// sortPropertySources 按照优先级从低到高对属性源进行稳定排序
+func sortPropertySources(sources []PropertySource) {
+	sort.SliceStable(sources, func(i, j int) bool {
+		return propertySourcePriority(sources[i]) < propertySourcePriority(sources[j])
+	})
+}
+
+// profileConfigNames 返回加载 profile 运行环境的配置时需要读取的配置文件名称，不含
+// 扩展名。非默认运行环境需要先读取 application 配置文件中属于该运行环境的文档。
+func profileConfigNames(profile string) []string {
+	if profile == "" {
+		return []string{"application"}
+	}
+	return []string{"application", "application-" + profile}
 }
 
 // defaultPropertySource 基于默认配置文件的属性源
 type defaultPropertySource struct {
-	fileLocation string // 配置文件所在目录
+	fileLocation string        // 配置文件所在目录
+	snapshot     *fileSnapshot // 加载过的配置文件的快照
 }
 
 // NewDefaultPropertySource defaultPropertySource 的构造函数
 func NewDefaultPropertySource(fileLocation string) *defaultPropertySource {
 	return &defaultPropertySource{
 		fileLocation: fileLocation,
+		snapshot:     newFileSnapshot(),
 	}
 }
 
 // Name 返回属性源的名称
 func (p *defaultPropertySource) Name() string {
-	return ""
+	return "file"
 }
 
 // Load 加载属性文件，profile 配置文件剖面。
-func (p *defaultPropertySource) Load(profile string) map[string]interface{} {
+func (p *defaultPropertySource) Load(profile string, active []string) SpringCore.Properties {
 
-	fileNamePrefix := "application"
-	if profile != "" {
-		fileNamePrefix += "-" + profile
-	}
+	result := SpringCore.NewDefaultProperties()
 
-	result := make(map[string]interface{})
+	// 先加载 application 配置文件中属于该运行环境的文档，再加载该运行环境专属的配置文件
+	for _, name := range profileConfigNames(profile) {
+		activate := activateFor(profile, active, name != "application")
 
-	// 从预定义的文件格式中加载属性值列表
-	for ext, reader := range configReaders {
+		// 按照注册的顺序从各种格式的配置文件中加载属性值列表
+		for _, ext := range configReaderExts {
+			reader := configReaders[ext]
 
-		filename := filepath.Join(p.fileLocation, fileNamePrefix+ext)
-		if _, err := os.Stat(filename); err != nil {
-			continue // 这里不需要警告
-		}
+			// 不存在的配置文件也要记录快照，这样才能发现新增的配置文件
+			filename := filepath.Join(p.fileLocation, name+ext)
+			p.snapshot.add(filename)
+			if !p.snapshot.exists(filename) {
+				continue // 这里不需要警告
+			}
 
-		SpringLogger.Info("load properties from file ", filename)
-		reader.ReadFile(filename, result)
+			SpringLogger.Info("load properties from file ", filename)
+			loadConfigFile(filename, reader, activate, result, p.snapshot)
+		}
 	}
 
 	return result
 }
 
+// Watch 轮询加载过的配置文件，配置文件发生变化时调用 onChange。
+func (p *defaultPropertySource) Watch(onChange func()) (stop func()) {
+	return pollWatch(fileWatchInterval, p.snapshot.changed, onChange)
+}
+
 // configMapPropertySource 基于 k8s ConfigMap 的属性源
 type configMapPropertySource struct {
-	filename string // 配置文件名称
+	filename string        // 配置文件名称
+	snapshot *fileSnapshot // 配置文件的快照
 }
 
 // NewConfigMapPropertySource configMapPropertySource 的构造函数
 func NewConfigMapPropertySource(filename string) *configMapPropertySource {
 	return &configMapPropertySource{
 		filename: filename,
+		snapshot: newFileSnapshot(),
 	}
 }
 
@@ -94,7 +200,9 @@ func (p *configMapPropertySource) Name() string {
 }
 
 // Load 加载属性文件，profile 配置文件剖面。
-func (p *configMapPropertySource) Load(profile string) map[string]interface{} {
+func (p *configMapPropertySource) Load(profile string, active []string) SpringCore.Properties {
+
+	p.snapshot.add(p.filename)
 
 	v := viper.New()
 	v.SetConfigFile(p.filename)
@@ -102,28 +210,119 @@ func (p *configMapPropertySource) Load(profile string) map[string]interface{} {
 	err := v.ReadInConfig()
 	SpringUtils.Panic(err).When(err != nil)
 
+	result := SpringCore.NewDefaultProperties()
+
 	d := v.Sub("data")
 	if d == nil {
-		return nil
+		return result
 	}
 
-	profileFileName := "application"
-	if profile != "" {
-		profileFileName += "-" + profile
-	}
+	for _, name := range profileConfigNames(profile) {
+		activate := activateFor(profile, active, name != "application")
 
-	result := make(map[string]interface{})
+		// 按照注册的顺序从各种格式的配置内容中加载属性值列表
+		for _, ext := range configReaderExts {
+			reader := configReaders[ext]
+			if key := name + ext; d.IsSet(key) {
+				source := p.filename + ":" + key
+				SpringLogger.Infof("load properties from config-map %s", source)
 
-	// 从预定义的文件格式中加载属性值列表
-	for ext, reader := range configReaders {
-		if key := profileFileName + ext; d.IsSet(key) {
-			SpringLogger.Infof("load properties from config-map %s:%s", p.filename, key)
-
-			if val := d.GetString(key); val != "" {
-				reader.ReadBuffer([]byte(val), result)
+				if val := d.GetString(key); val != "" {
+					loadConfigDocuments(reader, []byte(val), source, activate, result)
+				}
 			}
 		}
 	}
 
 	return result
 }
+
+// Watch 轮询配置文件，配置文件发生变化时调用 onChange。
+func (p *configMapPropertySource) Watch(onChange func()) (stop func()) {
+	return pollWatch(fileWatchInterval, p.snapshot.changed, onChange)
+}
+
+// fileWatchInterval 配置文件的轮询间隔
+var fileWatchInterval = 5 * time.Second
+
+// fileState 文件的状态，文件不存在时为零值
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// statFile 返回文件的当前状态
+func statFile(filename string) fileState {
+	if info, err := os.Stat(filename); err == nil {
+		return fileState{modTime: info.ModTime(), size: info.Size()}
+	}
+	return fileState{}
+}
+
+// fileSnapshot 记录文件的状态，用于轮询文件是否发生变化
+type fileSnapshot struct {
+	mutex sync.Mutex
+	files map[string]fileState
+}
+
+// newFileSnapshot fileSnapshot 的构造函数
+func newFileSnapshot() *fileSnapshot {
+	return &fileSnapshot{files: make(map[string]fileState)}
+}
+
+// add 记录文件的当前状态
+func (s *fileSnapshot) add(filename string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.files[filename] = statFile(filename)
+}
+
+// exists 返回记录的文件是否存在
+func (s *fileSnapshot) exists(filename string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.files[filename] != fileState{}
+}
+
+// changed 重新获取文件的状态，返回是否有文件发生了变化
+func (s *fileSnapshot) changed() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	changed := false
+	for filename, state := range s.files {
+		if curr := statFile(filename); curr != state {
+			s.files[filename] = curr
+			changed = true
+		}
+	}
+	return changed
+}
+
+// pollWatch 按照 interval 的间隔调用 poll，poll 返回 true 时调用 onChange，
+// 返回停止轮询的函数。interval 不大于 0 时不进行轮询。
+func pollWatch(interval time.Duration, poll func() bool, onChange func()) (stop func()) {
+
+	if interval <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if poll() {
+					onChange()
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
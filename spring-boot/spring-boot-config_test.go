@@ -17,9 +17,14 @@
 package SpringBoot
 
 import (
+	"encoding/base64"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/go-spring/go-spring/spring-core"
 	"github.com/magiconair/properties/assert"
@@ -84,10 +89,48 @@ func TestConfig(t *testing.T) {
 		_ = os.Setenv(SPRING_ACCESS, "")
 		_ = os.Setenv(SPRING_PROFILE, "dev")
 		app := startApplication("testdata/config/")
+		// 值为空的 SPRING_ACCESS 被忽略，使用配置文件中的 spring.access
 		assert.Equal(t, app.appCtx.AllAccess(), true)
 		assert.Equal(t, app.appCtx.GetProfile(), "dev")
 	})
 
+	t.Run("relaxed env and cmd args", func(t *testing.T) {
+		os.Clearenv()
+		_ = os.Setenv("WEB_SERVER_PORT", "9090")
+		_ = os.Setenv("WEB_SERVER_MAXIDLECONNS", "10")
+		args := os.Args
+		os.Args = []string{"app", "-web.server.sslPort", "8443"}
+		defer func() { os.Args = args }()
+		app := startApplication("testdata/config/")
+		assert.Equal(t, app.appCtx.GetProperty("web.server.port"), "9090")
+		assert.Equal(t, app.appCtx.GetProperty("web.server.max-idle-conns"), "10")
+		assert.Equal(t, app.appCtx.GetProperty("web.server.ssl-port"), "8443")
+		assert.Equal(t, app.appCtx.GetProperty("web.server.ssl_port"), "8443")
+		assert.Equal(t, app.appCtx.GetProperty("WEB_SERVER_SSL_PORT"), nil)
+	})
+
+	t.Run("property origins", func(t *testing.T) {
+		os.Clearenv()
+		_ = os.Setenv("WEB_SERVER_PORT", "9090")
+		args := os.Args
+		os.Args = []string{"app", "-web.server.sslPort", "8443"}
+		defer func() { os.Args = args }()
+		app := startApplication("testdata/config/", "k8s:testdata/config/config-map.yaml")
+
+		origin := func(key string) string {
+			o, ok := app.appCtx.GetPropertyOrigin(key)
+			assert.Equal(t, ok, true)
+			return o.String()
+		}
+
+		assert.Equal(t, origin("web.server.port"), "WEB_SERVER_PORT [system-env]")
+		assert.Equal(t, origin("web.server.ssl-port"), "-web.server.sslPort [cmd-args]")
+		assert.Equal(t, origin("spring.profile"), "testdata/config/application.properties:2 [app-config]")
+		assert.Equal(t, origin("default-value-ref"), "testdata/config/application-test.yaml:3 [profile-config]")
+		assert.Equal(t, origin("command-line-runner.collection"), "[api]")
+		assert.Equal(t, origin("message"), "testdata/config/config-map.yaml:application-test.yaml:1 [profile-config]")
+	})
+
 	t.Run("default expect system properties", func(t *testing.T) {
 		app := startApplication("testdata/config/")
 		for k, v := range app.appCtx.GetProperties() {
@@ -102,4 +145,279 @@ func TestConfig(t *testing.T) {
 			fmt.Println(k, v)
 		}
 	})
+
+	t.Run("default properties layer", func(t *testing.T) {
+		os.Clearenv()
+		app := newApplication(&defaultApplicationContext{
+			SpringContext: SpringCore.NewDefaultSpringContext(),
+		}, "testdata/config/")
+		app.defaults.SetProperty("default-only", "default")
+		app.defaults.SetProperty("default-value-ref", "default")
+		app.defaults.SetProperty("default-ref", "${default-only}")
+		app.appCtx.SetProperty("application-event.collection", "[]?")
+		app.appCtx.SetProperty("command-line-runner.collection", "[]?")
+		app.Start()
+		assert.Equal(t, app.appCtx.GetProperty("default-only"), "default")
+		assert.Equal(t, app.appCtx.GetProperty("default-value-ref"), "app-test")
+		assert.Equal(t, app.appCtx.GetProperty("default-ref"), "default")
+	})
+}
+
+func TestConfigReader_LocateKeys(t *testing.T) {
+
+	t.Run("properties", func(t *testing.T) {
+		buffer := []byte("# comment\na.b=1\nlong = 1,\\\n  2\nc.D:3\n")
+		lines := new(PropertiesReader).locateKeys(buffer)
+		assert.Equal(t, lines, map[string]int{"a.b": 2, "long": 3, "c.d": 5})
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		buffer := []byte("a:\n  b: 1\n  text: |-\n    c: 2\n  list:\n    - 3\nd: 4\n")
+		lines := (&ViperReader{"yaml"}).locateKeys(buffer)
+		assert.Equal(t, lines, map[string]int{"a": 1, "a.b": 2, "a.text": 3, "a.list": 5, "d": 7})
+	})
+
+	t.Run("toml", func(t *testing.T) {
+		buffer := []byte("a = 1\n[b]\nc = 2\n")
+		lines := (&ViperReader{"toml"}).locateKeys(buffer)
+		assert.Equal(t, lines, map[string]int{"a": 1, "b.c": 3})
+	})
+
+	t.Run("json", func(t *testing.T) {
+		buffer := []byte("{\n  \"a\": 1,\n  \"B\": {\n    \"c\": \"x\\\"y:\",\n    \"list\": [{\"d\": 2}]\n  },\n  \"e\": \"f\"\n}\n")
+		lines := (&ViperReader{"json"}).locateKeys(buffer)
+		assert.Equal(t, lines, map[string]int{"a": 2, "b": 3, "b.c": 4, "b.list": 5, "e": 7})
+	})
+}
+
+// memPropertySource 基于内存的属性源
+type memPropertySource struct {
+	name     string
+	priority int
+	values   map[string]map[string]interface{} // profile -> 属性值
+}
+
+func (p *memPropertySource) Name() string {
+	return p.name
+}
+
+func (p *memPropertySource) Priority() int {
+	return p.priority
+}
+
+func (p *memPropertySource) Load(profile string, active []string) SpringCore.Properties {
+	result := SpringCore.NewDefaultProperties()
+	for k, v := range p.values[profile] {
+		result.SetPropertyWithOrigin(k, v, SpringCore.PropertyOrigin{Source: p.name})
+	}
+	return result
+}
+
+func TestPropertySource(t *testing.T) {
+
+	RegisterPropertySourceFactory("mem", func(location string) PropertySource {
+		switch location {
+		case "high":
+			return &memPropertySource{name: "mem:high", priority: 10, values: map[string]map[string]interface{}{
+				"":     {"mem.value": "high"},
+				"test": {"mem.profile": "high-test"},
+			}}
+		default:
+			return &memPropertySource{name: "mem:" + location, values: map[string]map[string]interface{}{
+				"": {"mem.value": location, "mem.low": location},
+			}}
+		}
+	})
+	defer delete(propertySourceFactories, "mem")
+
+	t.Run("scheme", func(t *testing.T) {
+		assert.Equal(t, NewPropertySource("config/").Name(), "file")
+		assert.Equal(t, NewPropertySource("file:config/").Name(), "file")
+		assert.Equal(t, NewPropertySource("k8s:config/config-map.yaml").Name(), "k8s")
+		assert.Equal(t, NewPropertySource("mem:low").Name(), "mem:low")
+		assert.Equal(t, NewPropertySource(`C:\cfg`).(*defaultPropertySource).fileLocation, `C:\cfg`)
+		assert.Panic(t, func() {
+			NewPropertySource("vault:secret/app")
+		}, "unsupported property source scheme \"vault\"")
+		assert.Panic(t, func() {
+			RegisterPropertySourceFactory("mem", nil)
+		}, "duplicate registration, property source: \"mem\"")
+	})
+
+	t.Run("priority", func(t *testing.T) {
+		os.Clearenv()
+		app := startApplication("mem:high", "testdata/config/", "mem:low")
+		assert.Equal(t, app.appCtx.GetProperty("mem.value"), "high")
+		assert.Equal(t, app.appCtx.GetProperty("mem.low"), "low")
+		assert.Equal(t, app.appCtx.GetProperty("mem.profile"), "high-test")
+		origin, _ := app.appCtx.GetPropertyOrigin("mem.value")
+		assert.Equal(t, origin.String(), "mem:high [app-config]")
+	})
+}
+
+type reloadConfig struct {
+	Enabled bool `value:"${feature.enabled}"`
+	Limit   int  `value:"${rate.limit}"`
+}
+
+type reloadListener struct {
+	events chan PropertyChangeEvent
+}
+
+func (l *reloadListener) OnPropertyChange(ctx ApplicationContext, event PropertyChangeEvent) {
+	l.events <- event
+}
+
+func TestApplication_Reload(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "reload")
+	assert.Equal(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "application.properties")
+	writeConfig := func(content string) {
+		err := ioutil.WriteFile(filename, []byte(content), 0644)
+		assert.Equal(t, err, nil)
+	}
+
+	startReloadApp := func() (*application, *reloadConfig, *reloadListener) {
+		app := newApplication(&defaultApplicationContext{
+			SpringContext: SpringCore.NewDefaultSpringContext(),
+		}, dir)
+		c := new(reloadConfig)
+		l := &reloadListener{events: make(chan PropertyChangeEvent, 10)}
+		app.appCtx.RegisterBean(c).Refreshable()
+		app.appCtx.RegisterBean(l).Export((*PropertyChangeListener)(nil))
+		app.appCtx.SetProperty("application-event.collection", "[]?")
+		app.appCtx.SetProperty("command-line-runner.collection", "[]?")
+		app.appCtx.SetProperty(SpringConfigReloadEnabled, true)
+		appCtx := app.appCtx
+		app.appCtx.Config(func() { appCtx.SetProperty("config.value", "set-by-config") })
+		app.Start()
+		return app, c, l
+	}
+
+	waitEvent := func(t *testing.T, l *reloadListener) PropertyChangeEvent {
+		select {
+		case event := <-l.events:
+			return event
+		case <-time.After(time.Second):
+			t.Fatal("property change event not received")
+		}
+		return PropertyChangeEvent{}
+	}
+
+	interval := fileWatchInterval
+	defer func() { fileWatchInterval = interval }()
+
+	t.Run("api and signal", func(t *testing.T) {
+		fileWatchInterval = time.Hour
+		writeConfig("feature.enabled=false\nrate.limit=10\n")
+		app, c, l := startReloadApp()
+		defer app.ShutDown()
+		assert.Equal(t, c.Limit, 10)
+
+		writeConfig("feature.enabled=false\nrate.limit=20\n")
+		assert.Equal(t, app.Reload(), nil)
+		assert.Equal(t, waitEvent(t, l).Keys, []string{"rate.limit"})
+		assert.Equal(t, c.Limit, 20)
+
+		// Config 函数设置的属性值在重新加载时保留
+		assert.Equal(t, app.appCtx.GetProperty("config.value"), "set-by-config")
+
+		// 绑定失败时属性值和 Bean 保持不变
+		writeConfig("feature.enabled=false\nrate.limit=abc\n")
+		assert.Matches(t, app.Reload().Error(), "property value rate.limit isn't int type")
+		assert.Equal(t, c.Limit, 20)
+		assert.Equal(t, app.appCtx.GetProperty("rate.limit"), "20")
+
+		writeConfig("feature.enabled=false\nrate.limit=30\n")
+		process, _ := os.FindProcess(os.Getpid())
+		assert.Equal(t, process.Signal(syscall.SIGHUP), nil)
+		assert.Equal(t, waitEvent(t, l).Keys, []string{"rate.limit"})
+		assert.Equal(t, c.Limit, 30)
+	})
+
+	t.Run("profiles and decryptor", func(t *testing.T) {
+		defer SpringCore.SetPropertyDecryptor(nil)
+		fileWatchInterval = time.Hour
+		writeConfig("feature.enabled=false\nrate.limit=10\n")
+		app, c, _ := startReloadApp()
+		defer app.ShutDown()
+
+		key := []byte("0123456789abcdef")
+		keyFile := filepath.Join(dir, "aes.key")
+		err := ioutil.WriteFile(keyFile, []byte(base64.StdEncoding.EncodeToString(key)), 0644)
+		assert.Equal(t, err, nil)
+		d, err := SpringCore.NewAESGCMDecryptor(key)
+		assert.Equal(t, err, nil)
+		limit, err := d.Encrypt("42")
+		assert.Equal(t, err, nil)
+
+		// 重新加载时使用新配置的秘钥解密属性值
+		writeConfig("feature.enabled=false\nrate.limit=" + limit + "\n" +
+			SpringConfigEncryptKeyFile + "=" + keyFile + "\n")
+		assert.Equal(t, app.Reload(), nil)
+		assert.Equal(t, c.Limit, 42)
+
+		// 运行环境不能通过重新加载改变
+		writeConfig("feature.enabled=false\nrate.limit=50\n" + SpringProfile + "=dev\n")
+		assert.Matches(t, app.Reload().Error(), "profiles can't be changed by reload")
+		assert.Equal(t, c.Limit, 42)
+		assert.Equal(t, len(app.appCtx.GetProfiles()), 0)
+	})
+
+	t.Run("file watch", func(t *testing.T) {
+		fileWatchInterval = 10 * time.Millisecond
+		writeConfig("feature.enabled=false\nrate.limit=10\n")
+		app, c, l := startReloadApp()
+		defer app.ShutDown()
+		assert.Equal(t, c.Enabled, false)
+
+		writeConfig("feature.enabled=true\nrate.limit=10\n")
+		assert.Equal(t, waitEvent(t, l).Keys, []string{"feature.enabled"})
+		assert.Equal(t, c.Enabled, true)
+	})
+}
+
+type indexedServer struct {
+	Host string `value:"${host}"`
+	Port int    `value:"${port:=80}"`
+}
+
+type indexedClient struct {
+	Servers []indexedServer   `value:"${client.servers}"`
+	Headers map[string]string `value:"${client.headers}" case:"preserve"`
+}
+
+func TestIndexedProperties(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "indexed-properties")
+	assert.Equal(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	writeConfigTree(t, dir, map[string]string{
+		"application.yaml": "spring.profile: prod\nclient:\n  servers:\n    - host: a\n      port: 8080\n    - host: b\n",
+		"application-prod.properties": "client.servers[0].host=prod\n" +
+			"client.headers.X-Request-Id=1\n" +
+			"client.headers.Content-Type=text/plain\n",
+	})
+
+	app := newApplication(&defaultApplicationContext{
+		SpringContext: SpringCore.NewDefaultSpringContext(),
+	}, dir)
+	app.appCtx.SetProperty("application-event.collection", "[]?")
+	app.appCtx.SetProperty("command-line-runner.collection", "[]?")
+
+	client := new(indexedClient)
+	app.appCtx.RegisterBean(client)
+	app.Start()
+	defer app.ShutDown()
+
+	// 运行环境的配置文件中的列表整体覆盖默认配置文件中的列表
+	assert.Equal(t, client.Servers, []indexedServer{{Host: "prod", Port: 80}})
+	assert.Equal(t, client.Headers, map[string]string{"X-Request-Id": "1", "Content-Type": "text/plain"})
+
+	origin, _ := app.appCtx.GetPropertyOrigin("client.servers[0].host")
+	assert.Equal(t, origin.String(), filepath.Join(dir, "application-prod.properties")+":1 [profile-config]")
 }
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringBoot
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/go-spring/go-spring/spring-core"
+)
+
+const (
+	SpringConfigEncryptKeyFile = "spring.config.encrypt.key-file" // 加密属性值的秘钥文件，内容为 base64 编码的 AES 秘钥
+	SPRING_CONFIG_ENCRYPT_KEY  = "SPRING_CONFIG_ENCRYPT_KEY"      // 加密属性值的秘钥，base64 编码的 AES 秘钥
+)
+
+// springConfigEncryptPrefix 加密配置的属性名前缀，这些属性值在日志和诊断输出中隐藏
+const springConfigEncryptPrefix = "spring.config.encrypt."
+
+// isEncryptConfigKey 返回是否是加密配置的属性名，比如秘钥和秘钥文件。
+func isEncryptConfigKey(key string) bool {
+	return strings.HasPrefix(SpringCore.CanonicalPropertyKey(key), springConfigEncryptPrefix)
+}
+
+// maskProperties 返回用于日志和诊断输出的所有未经解析的属性值，加密的属性值、引用
+// 了加密属性值的属性值以及加密配置的属性值被替换为 ******。
+func maskProperties(p SpringCore.Properties) map[string]interface{} {
+	result := SpringCore.MaskProperties(p)
+	for k := range result {
+		if isEncryptConfigKey(k) {
+			result[k] = SpringCore.MaskedPropertyValue
+		}
+	}
+	return result
+}
+
+// loadPropertyDecryptor 根据配置的秘钥创建 AES-GCM 解密器，环境变量中的秘钥优先于
+// 秘钥文件。没有配置秘钥时返回 nil，此时保留通过代码设置的解密器。
+func loadPropertyDecryptor(p SpringCore.Properties) (SpringCore.PropertyDecryptor, error) {
+
+	if _, ok := os.LookupEnv(SPRING_CONFIG_ENCRYPT_KEY); ok {
+		return SpringCore.NewAESGCMDecryptorFromEnv(SPRING_CONFIG_ENCRYPT_KEY)
+	}
+
+	if file := p.GetStringProperty(SpringConfigEncryptKeyFile); file != "" {
+		return SpringCore.NewAESGCMDecryptorFromFile(file)
+	}
+
+	return nil, nil
+}
+
+// encryptValues 使用当前的解密器加密 values，每行输出一个 ENC(...) 形式的属性值，
+// 可以直接写入配置文件。
+func encryptValues(w io.Writer, values []string) error {
+
+	e, ok := SpringCore.GetPropertyDecryptor().(SpringCore.PropertyEncryptor)
+	if !ok {
+		return fmt.Errorf("no property encryptor, set %s or %s", SPRING_CONFIG_ENCRYPT_KEY, SpringConfigEncryptKeyFile)
+	}
+
+	if len(values) == 0 {
+		return errors.New("no value to encrypt")
+	}
+
+	for _, v := range values {
+		s, err := e.Encrypt(v)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, s)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringBoot
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/go-spring/go-spring/spring-core"
+)
+
+// propertyMetadata 注册的属性，包括 SpringBoot 内置的属性和 starter 发布的属性
+var propertyMetadata = []SpringCore.PropertyMetadata{
+	{Name: SpringAccess, Type: "string", Description: "是否允许注入私有字段"},
+	{Name: SpringProfile, Type: "string", Description: "设置运行环境，多个运行环境使用逗号分隔"},
+	{Name: SpringProfilesInclude, Type: "[]string", Description: "额外包含的运行环境"},
+	{Name: SpringConfigImport, Type: "[]string", Description: "导入其他配置文件"},
+	{Name: SpringConfigStrictMode, Type: "string", DefaultValue: strictModeOff, HasDefault: true, Description: "未使用属性的处理方式，off、warn 或者 fail"},
+	{Name: SpringConfigStrictIgnore, Type: "[]string", Description: "严格模式忽略的属性名前缀"},
+	{Name: SpringConfigEncryptKeyFile, Type: "string", Description: "加密属性值的秘钥文件"},
+	{Name: SpringConfigReloadEnabled, Type: "bool", DefaultValue: "false", HasDefault: true, Description: "是否开启属性值的动态刷新"},
+}
+
+// RegisterPropertyMetadata 注册结构体通过 value 标签声明的属性。starter 使用它发布
+// 不是 Bean 的配置结构体，比如作为构造函数参数的配置结构体，这样即使应用没有使用
+// 这些配置结构体，生成的属性元数据中也包含它们。
+func RegisterPropertyMetadata(structs ...interface{}) {
+	metadata := SpringCore.CollectStructMetadata(structs...)
+	propertyMetadata = append(propertyMetadata, metadata...)
+}
+
+// booleanProperties 返回注册的布尔型属性以及自动配置模块的 Bean 声明的布尔型属性
+// 的属性名，解析命令行时它们是布尔开关。
+func booleanProperties(modules []*Module) map[string]bool {
+	metadata := propertyMetadata
+	for _, m := range modules {
+		metadata = append(metadata[:len(metadata):len(metadata)], SpringCore.CollectPropertyMetadata(m.beans...)...)
+	}
+	result := make(map[string]bool)
+	for _, m := range metadata {
+		if m.Type == "bool" {
+			result[SpringCore.CanonicalPropertyKey(m.Name)] = true
+		}
+	}
+	return result
+}
+
+// GetPropertyMetadata 返回全局上下文中所有 Bean 声明的属性以及注册的属性，按照属性名排序。
+func GetPropertyMetadata() []SpringCore.PropertyMetadata {
+	return collectPropertyMetadata(ctx)
+}
+
+// collectPropertyMetadata 返回上下文中所有 Bean 声明的属性以及注册的属性
+func collectPropertyMetadata(ctx SpringCore.SpringContext) []SpringCore.PropertyMetadata {
+	beans := SpringCore.CollectPropertyMetadata(ctx.GetBeanDefinitions()...)
+	return SpringCore.MergePropertyMetadata(propertyMetadata, beans)
+}
+
+// PropertyMetadataSchema 根据属性元数据生成 JSON Schema，属性名按照 . 展开为嵌套的
+// 对象，编辑器可以用它对 application.yaml 进行补全和校验。
+func PropertyMetadataSchema(metadata []SpringCore.PropertyMetadata) map[string]interface{} {
+
+	newObject := func() map[string]interface{} {
+		return map[string]interface{}{
+			"type":       "object",
+			"properties": make(map[string]interface{}),
+		}
+	}
+
+	root := newObject()
+	root["$schema"] = "http://json-schema.org/draft-07/schema#"
+
+	for _, m := range metadata {
+		node := root
+		segments := strings.Split(m.Name, ".")
+
+		for _, s := range segments[:len(segments)-1] {
+			properties := node["properties"].(map[string]interface{})
+			child, ok := properties[s].(map[string]interface{})
+			if !ok || child["properties"] == nil {
+				child = newObject()
+				properties[s] = child
+			}
+			node = child
+		}
+
+		properties := node["properties"].(map[string]interface{})
+		properties[segments[len(segments)-1]] = propertySchema(m)
+	}
+	return root
+}
+
+// propertySchema 返回单个属性的 JSON Schema
+func propertySchema(m SpringCore.PropertyMetadata) map[string]interface{} {
+
+	schema := goTypeSchema(m.Type)
+	if m.Description != "" {
+		schema["description"] = m.Description
+	}
+
+	// 包含引用的默认值在运行时才能确定
+	if m.HasDefault && !strings.Contains(m.DefaultValue, "${") {
+		var (
+			def interface{}
+			err error
+		)
+		switch schema["type"] {
+		case "boolean":
+			def, err = strconv.ParseBool(m.DefaultValue)
+		case "integer":
+			def, err = strconv.ParseInt(m.DefaultValue, 10, 64)
+		case "number":
+			def, err = strconv.ParseFloat(m.DefaultValue, 64)
+		case "string":
+			def = m.DefaultValue
+		default:
+			err = strconv.ErrSyntax
+		}
+		if err == nil {
+			schema["default"] = def
+		}
+	}
+	return schema
+}
+
+// goTypeSchema 将 Go 类型转换为 JSON Schema，无法识别的类型不做限制。
+func goTypeSchema(goType string) map[string]interface{} {
+
+	if strings.HasPrefix(goType, "[]") {
+		return map[string]interface{}{
+			"type":  "array",
+			"items": goTypeSchema(goType[2:]),
+		}
+	}
+
+	if strings.HasPrefix(goType, "map[") {
+		if i := strings.Index(goType, "]"); i > 0 {
+			return map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": goTypeSchema(goType[i+1:]),
+			}
+		}
+	}
+
+	switch goType {
+	case "bool":
+		return map[string]interface{}{"type": "boolean"}
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return map[string]interface{}{"type": "integer"}
+	case "float32", "float64":
+		return map[string]interface{}{"type": "number"}
+	case "string", "time.Duration":
+		return map[string]interface{}{"type": "string"}
+	case "time.Time":
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	return make(map[string]interface{})
+}
+
+// writeJSON 以缩进的格式输出 JSON
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,144 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringBoot
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/go-spring/go-spring/spring-core"
+	"github.com/magiconair/properties/assert"
+)
+
+func TestPropertyMetadataSchema(t *testing.T) {
+
+	schema := PropertyMetadataSchema([]SpringCore.PropertyMetadata{
+		{Name: "server.host", Type: "string", DefaultValue: "localhost", HasDefault: true, Description: "主机名"},
+		{Name: "server.port", Type: "int", DefaultValue: "8080", HasDefault: true},
+		{Name: "server.ratio", Type: "float64", DefaultValue: "${ratio}", HasDefault: true},
+		{Name: "server.tags", Type: "[]string"},
+		{Name: "server.labels", Type: "map[string]int"},
+		{Name: "server.timeout", Type: "time.Duration", DefaultValue: "5s", HasDefault: true},
+		{Name: "debug", Type: "bool", DefaultValue: "false", HasDefault: true},
+		{Name: "custom", Type: "*net.IPNet"},
+	})
+
+	var buf bytes.Buffer
+	assert.Equal(t, writeJSON(&buf, schema), nil)
+
+	var actual map[string]interface{}
+	assert.Equal(t, json.Unmarshal(buf.Bytes(), &actual), nil)
+
+	var expect map[string]interface{}
+	assert.Equal(t, json.Unmarshal([]byte(`{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type": "object",
+		"properties": {
+			"custom": {},
+			"debug": {"type": "boolean", "default": false},
+			"server": {
+				"type": "object",
+				"properties": {
+					"host": {"type": "string", "default": "localhost", "description": "主机名"},
+					"labels": {"type": "object", "additionalProperties": {"type": "integer"}},
+					"port": {"type": "integer", "default": 8080},
+					"ratio": {"type": "number"},
+					"tags": {"type": "array", "items": {"type": "string"}},
+					"timeout": {"type": "string", "default": "5s"}
+				}
+			}
+		}
+	}`), &expect), nil)
+
+	assert.Equal(t, actual, expect)
+}
+
+func TestRunCommand(t *testing.T) {
+
+	exit := exitAfterCommand
+	defer func() { exitAfterCommand = exit }()
+
+	exited := false
+	exitAfterCommand = func() { exited = true }
+
+	ctx := SpringCore.NewDefaultSpringContext()
+	ctx.RegisterBean(new(helpServer))
+	metadata := collectPropertyMetadata(ctx)
+
+	var buf bytes.Buffer
+	runCommand(&buf, &commandLine{command: configMetadataCommand}, "my-app", metadata)
+	assert.Equal(t, exited, true)
+
+	var result []SpringCore.PropertyMetadata
+	assert.Equal(t, json.Unmarshal(buf.Bytes(), &result), nil)
+	assert.Equal(t, result, metadata)
+
+	// 内置属性和 Bean 声明的属性都在元数据中
+	names := make(map[string]SpringCore.PropertyMetadata)
+	for _, m := range result {
+		names[m.Name] = m
+	}
+	assert.Equal(t, names["spring.profile"].Description, "设置运行环境，多个运行环境使用逗号分隔")
+	assert.Equal(t, names["server.port"].SourceType, "SpringBoot.helpServerConfig")
+	assert.Equal(t, names["server.port"].Field, "Port")
+
+	buf.Reset()
+	runCommand(&buf, &commandLine{command: configSchemaCommand}, "my-app", metadata)
+	assert.Matches(t, buf.String(), `"\$schema": "http://json-schema.org/draft-07/schema#"`)
+}
+
+// unavailablePropertySource 无法访问的远程属性源
+type unavailablePropertySource struct{}
+
+func (p *unavailablePropertySource) Name() string {
+	return "remote"
+}
+
+func (p *unavailablePropertySource) Load(profile string, active []string) SpringCore.Properties {
+	panic(errors.New("connection refused"))
+}
+
+func TestStartCommand(t *testing.T) {
+
+	RegisterPropertySourceFactory("remote", func(location string) PropertySource {
+		return new(unavailablePropertySource)
+	})
+	defer delete(propertySourceFactories, "remote")
+
+	exit := exitAfterCommand
+	defer func() { exitAfterCommand = exit }()
+
+	exited := false
+	exitAfterCommand = func() { exited = true }
+
+	args := os.Args
+	defer func() { os.Args = args }()
+
+	// 远程属性源不可用时仍然可以打印帮助信息
+	os.Args = []string{"app", "--help"}
+	startApplication("remote:config", "testdata/config/")
+	assert.Equal(t, exited, true)
+
+	// 正常启动时远程属性源加载失败会 panic
+	os.Args = []string{"app"}
+	assert.Panic(t, func() {
+		startApplication("remote:config", "testdata/config/")
+	}, "connection refused")
+}
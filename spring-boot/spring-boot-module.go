@@ -0,0 +1,306 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringBoot
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-spring/go-spring-parent/spring-logger"
+	"github.com/go-spring/go-spring-parent/spring-utils"
+	"github.com/go-spring/go-spring-web/spring-web"
+	"github.com/go-spring/go-spring/spring-core"
+	"github.com/go-spring/go-spring/spring-core/sort"
+	"github.com/spf13/cast"
+)
+
+const (
+	SpringAutoConfigureExclude   = "spring.autoconfigure.exclude" // 排除的自动配置模块
+	SPRING_AUTOCONFIGURE_EXCLUDE = "SPRING_AUTOCONFIGURE_EXCLUDE"
+)
+
+// modules 全局的自动配置模块列表
+var modules []*Module
+
+// Module 自动配置模块。模块内注册的 Bean、Configer、路由和 gRPC 服务不会立即
+// 注册到上下文，而是在应用启动时按照模块的顺序统一注册，这样就可以通过属性值
+// spring.autoconfigure.exclude 整体排除一个模块。
+type Module struct {
+	name   string
+	before []string // 位于哪些模块之前
+	after  []string // 位于哪些模块之后
+
+	beans       []*SpringCore.BeanDefinition
+	configers   []*SpringCore.Configer
+	mapping     *WebMapping
+	gRpcServers map[reflect.Value]*GRpcServer
+}
+
+// NewModule Module 的构造函数
+func NewModule(name string) *Module {
+	if name == "" {
+		panic(errors.New("module name can't be empty"))
+	}
+	return &Module{
+		name:        name,
+		mapping:     NewWebMapping(),
+		gRpcServers: make(map[reflect.Value]*GRpcServer),
+	}
+}
+
+// AutoConfiguration 注册一个自动配置模块，重复注册会 panic。
+func AutoConfiguration(name string) *Module {
+	for _, m := range modules {
+		if m.name == name {
+			panic(fmt.Errorf("duplicate registration, module: \"%s\"", name))
+		}
+	}
+	m := NewModule(name)
+	modules = append(modules, m)
+	return m
+}
+
+// Name 返回模块的名称
+func (m *Module) Name() string {
+	return m.name
+}
+
+// Before 设置当前模块在某些模块之前注册
+func (m *Module) Before(modules ...string) *Module {
+	m.before = append(m.before, modules...)
+	return m
+}
+
+// After 设置当前模块在某些模块之后注册
+func (m *Module) After(modules ...string) *Module {
+	m.after = append(m.after, modules...)
+	return m
+}
+
+// RegisterBean 注册单例 Bean，不指定名称，重复注册会 panic。
+func (m *Module) RegisterBean(bean interface{}) *SpringCore.BeanDefinition {
+	return m.RegisterNameBean("", bean)
+}
+
+// RegisterNameBean 注册单例 Bean，需指定名称，重复注册会 panic。
+func (m *Module) RegisterNameBean(name string, bean interface{}) *SpringCore.BeanDefinition {
+	bd := SpringCore.ToBeanDefinition(name, bean)
+	m.beans = append(m.beans, bd)
+	return bd
+}
+
+// RegisterConfigurationProperties 注册配置属性 Bean，bean 必须是结构体指针，字段
+// 的 value 标签使用相对于 prefix 的属性名，Bean 的名称是 prefix，重复注册会 panic。
+func (m *Module) RegisterConfigurationProperties(prefix string, bean interface{}) *SpringCore.BeanDefinition {
+	bd := SpringCore.ConfigurationToBeanDefinition(prefix, bean)
+	m.beans = append(m.beans, bd)
+	return bd
+}
+
+// RegisterBeanFn 注册单例构造函数 Bean，不指定名称，重复注册会 panic。
+func (m *Module) RegisterBeanFn(fn interface{}, tags ...string) *SpringCore.BeanDefinition {
+	return m.RegisterNameBeanFn("", fn, tags...)
+}
+
+// RegisterNameBeanFn 注册单例构造函数 Bean，需指定名称，重复注册会 panic。
+func (m *Module) RegisterNameBeanFn(name string, fn interface{}, tags ...string) *SpringCore.BeanDefinition {
+	bd := SpringCore.FnToBeanDefinition(name, fn, tags...)
+	m.beans = append(m.beans, bd)
+	return bd
+}
+
+// RegisterMethodBean 注册成员方法单例 Bean，不指定名称，重复注册会 panic。
+func (m *Module) RegisterMethodBean(selector SpringCore.BeanSelector, method string, tags ...string) *SpringCore.BeanDefinition {
+	return m.RegisterNameMethodBean("", selector, method, tags...)
+}
+
+// RegisterNameMethodBean 注册成员方法单例 Bean，需指定名称，重复注册会 panic。
+func (m *Module) RegisterNameMethodBean(name string, selector SpringCore.BeanSelector, method string, tags ...string) *SpringCore.BeanDefinition {
+	if selector == nil || selector == "" {
+		panic(errors.New("selector can't be nil or empty"))
+	}
+	bd := SpringCore.MethodToBeanDefinition(name, selector, method, tags...)
+	m.beans = append(m.beans, bd)
+	return bd
+}
+
+// RegisterFilter 注册 Web Filter 对象 Bean，不指定名称，重复注册会 panic。
+func (m *Module) RegisterFilter(bean interface{}) *SpringCore.BeanDefinition {
+	return m.RegisterBean(bean).Export((*SpringWeb.Filter)(nil))
+}
+
+// RegisterFilterFn 注册 Web Filter 构造函数 Bean，不指定名称，重复注册会 panic。
+func (m *Module) RegisterFilterFn(fn interface{}, tags ...string) *SpringCore.BeanDefinition {
+	return m.RegisterBeanFn(fn, tags...).Export((*SpringWeb.Filter)(nil))
+}
+
+// Config 注册一个配置函数
+func (m *Module) Config(fn interface{}, tags ...string) *SpringCore.Configer {
+	return m.ConfigWithName("", fn, tags...)
+}
+
+// ConfigWithName 注册一个配置函数，名称的作用是对 Config 进行排重和排顺序。
+func (m *Module) ConfigWithName(name string, fn interface{}, tags ...string) *SpringCore.Configer {
+	configer := SpringCore.NewConfiger(name, fn, tags)
+	m.configers = append(m.configers, configer)
+	return configer
+}
+
+// Route 返回和模块绑定的路由分组
+func (m *Module) Route(basePath string, filters ...SpringWeb.Filter) *Router {
+	return newRouter(m.mapping, basePath, filters)
+}
+
+// Request 注册任意 HTTP 方法处理函数
+func (m *Module) Request(method uint32, path string, fn interface{}, filters ...SpringWeb.Filter) *Mapping {
+	return m.mapping.Request(method, path, fn, filters)
+}
+
+// RegisterGRpcServer 注册 gRPC 服务，fn 是 gRPC 自动生成的服务注册函数
+func (m *Module) RegisterGRpcServer(fn interface{}, server interface{}) *GRpcServer {
+	v := reflect.ValueOf(fn)
+	if _, ok := m.gRpcServers[v]; ok {
+		_, _, fnName := SpringUtils.FileLine(fn)
+		panic(fmt.Errorf("duplicate registration, gRpcServer: %s", fnName))
+	}
+	s := newGRpcServer(server)
+	m.gRpcServers[v] = s
+	return s
+}
+
+// apply 将模块内的注册项注册到上下文、全局路由表和全局 gRPC 服务列表
+func (m *Module) apply(ctx ApplicationContext) {
+
+	for _, bd := range m.beans {
+		ctx.RegisterBeanDefinition(bd)
+	}
+
+	for _, configer := range m.configers {
+		ctx.RegisterConfiger(configer)
+	}
+
+	for key, mapping := range m.mapping.Mappings {
+		if _, ok := DefaultWebMapping.Mappings[key]; ok {
+			panic(fmt.Errorf("duplicate registration, mapping: %s", key))
+		}
+		DefaultWebMapping.Mappings[key] = mapping
+	}
+
+	for fn, server := range m.gRpcServers {
+		if _, ok := GRpcServerMap[fn]; ok {
+			_, _, fnName := SpringUtils.FileLine(fn.Interface())
+			panic(fmt.Errorf("duplicate registration, gRpcServer: %s", fnName))
+		}
+		GRpcServerMap[fn] = server
+	}
+}
+
+// String 返回模块注册项的统计信息
+func (m *Module) String() string {
+	return fmt.Sprintf("%s (beans: %d, configers: %d, mappings: %d, gRpcServers: %d)",
+		m.name, len(m.beans), len(m.configers), len(m.mapping.Mappings), len(m.gRpcServers))
+}
+
+// getBeforeModules 获取排在当前模块前面的模块列表
+func getBeforeModules(modules *list.List, i interface{}) *list.List {
+	result := list.New()
+	current := i.(*Module)
+	for e := modules.Front(); e != nil; e = e.Next() {
+		m := e.Value.(*Module)
+
+		// 检查是否在当前模块的前面
+		for _, name := range m.before {
+			if current.name == name {
+				result.PushBack(m)
+			}
+		}
+
+		// 检查是否在当前模块的前面
+		for _, name := range current.after {
+			if m.name == name {
+				result.PushBack(m)
+			}
+		}
+	}
+	return result
+}
+
+// parseModuleNames 解析属性值中的模块名称列表，支持逗号分隔的字符串和列表
+func parseModuleNames(value interface{}) []string {
+	if value == nil {
+		return nil
+	}
+
+	if s, ok := value.(string); ok {
+		value = strings.Split(s, ",")
+	}
+
+	names, err := cast.ToStringSliceE(value)
+	SpringUtils.Panic(err).When(err != nil)
+
+	var result []string
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// resolveModules 排除指定的模块，然后对剩余的模块进行排序
+func resolveModules(modules []*Module, exclude []string) (applied []*Module, excluded []*Module) {
+
+	l := list.New()
+	for _, m := range modules {
+		if SpringUtils.ContainsString(exclude, m.name) >= 0 {
+			excluded = append(excluded, m)
+		} else {
+			l.PushBack(m)
+		}
+	}
+
+	sorted := sort.TripleSorting(l, getBeforeModules)
+	for e := sorted.Front(); e != nil; e = e.Next() {
+		applied = append(applied, e.Value.(*Module))
+	}
+	return
+}
+
+// applyModules 注册未被排除的自动配置模块并打印模块报告
+func (app *application) applyModules() {
+
+	keys := []string{SpringAutoConfigureExclude, SPRING_AUTOCONFIGURE_EXCLUDE}
+	exclude := parseModuleNames(app.appCtx.GetProperty(keys...))
+
+	applied, excluded := resolveModules(app.modules, exclude)
+	for _, m := range applied {
+		m.apply(app.appCtx)
+	}
+
+	if len(applied) > 0 || len(excluded) > 0 {
+		SpringLogger.Info("auto configuration report:")
+		for _, m := range applied {
+			SpringLogger.Info("  [applied] ", m)
+		}
+		for _, m := range excluded {
+			SpringLogger.Info("  [excluded] ", m)
+		}
+	}
+}
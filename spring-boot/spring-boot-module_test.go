@@ -0,0 +1,145 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringBoot
+
+import (
+	"os"
+	"testing"
+
+	"github.com/go-spring/go-spring-web/spring-web"
+	"github.com/go-spring/go-spring/spring-core"
+	"github.com/magiconair/properties/assert"
+)
+
+type moduleBean struct {
+	name string
+}
+
+func TestResolveModules(t *testing.T) {
+
+	t.Run("sorted", func(t *testing.T) {
+		a := NewModule("a").After("b")
+		b := NewModule("b")
+		c := NewModule("c").Before("b")
+
+		applied, excluded := resolveModules([]*Module{a, b, c}, nil)
+		assert.Equal(t, applied, []*Module{c, b, a})
+		assert.Equal(t, len(excluded), 0)
+	})
+
+	t.Run("excluded", func(t *testing.T) {
+		a := NewModule("a").After("b")
+		b := NewModule("b")
+		c := NewModule("c").Before("b")
+
+		applied, excluded := resolveModules([]*Module{a, b, c}, []string{"b"})
+		assert.Equal(t, applied, []*Module{a, c})
+		assert.Equal(t, excluded, []*Module{b})
+	})
+
+	t.Run("found cycle", func(t *testing.T) {
+		a := NewModule("a").After("b")
+		b := NewModule("b").After("a")
+		assert.Panic(t, func() {
+			resolveModules([]*Module{a, b}, nil)
+		}, "found sorting cycle")
+	})
+}
+
+func TestParseModuleNames(t *testing.T) {
+	assert.Equal(t, parseModuleNames(nil), []string(nil))
+	assert.Equal(t, parseModuleNames("a, b,,c"), []string{"a", "b", "c"})
+	assert.Equal(t, parseModuleNames([]interface{}{"a", "b"}), []string{"a", "b"})
+}
+
+func TestApplication_ApplyModules(t *testing.T) {
+
+	newModules := func() []*Module {
+		a := NewModule("module-a")
+		a.RegisterNameBean("a", &moduleBean{"a"})
+		a.Config(func() {})
+
+		b := NewModule("module-b").After("module-a")
+		b.RegisterNameBean("b", &moduleBean{"b"})
+		b.Route("/module").GetMapping("/b", func(ctx SpringWeb.WebContext) {})
+
+		return []*Module{a, b}
+	}
+
+	t.Run("applied", func(t *testing.T) {
+		os.Clearenv()
+		app := newApplication(&defaultApplicationContext{
+			SpringContext: SpringCore.NewDefaultSpringContext(),
+		})
+		app.modules = newModules()
+		app.appCtx.SetProperty("application-event.collection", "[]?")
+		app.appCtx.SetProperty("command-line-runner.collection", "[]?")
+		app.Start()
+
+		var beans []*moduleBean
+		app.appCtx.CollectBeans(&beans)
+		assert.Equal(t, len(beans), 2)
+
+		found := false
+		for key, mapping := range DefaultWebMapping.Mappings {
+			if mapping.Path() == "/module/b" {
+				delete(DefaultWebMapping.Mappings, key)
+				found = true
+			}
+		}
+		assert.Equal(t, found, true)
+	})
+
+	t.Run("excluded", func(t *testing.T) {
+		os.Clearenv()
+		app := newApplication(&defaultApplicationContext{
+			SpringContext: SpringCore.NewDefaultSpringContext(),
+		})
+		app.modules = newModules()
+		app.appCtx.SetProperty("application-event.collection", "[]?")
+		app.appCtx.SetProperty("command-line-runner.collection", "[]?")
+		app.appCtx.SetProperty(SpringAutoConfigureExclude, "module-a")
+		app.Start()
+
+		var beans []*moduleBean
+		app.appCtx.CollectBeans(&beans)
+		assert.Equal(t, len(beans), 1)
+		assert.Equal(t, beans[0].name, "b")
+	})
+
+	t.Run("duplicate mapping", func(t *testing.T) {
+		a := NewModule("module-a")
+		a.Route("/module").GetMapping("/dup", func(ctx SpringWeb.WebContext) {})
+
+		b := NewModule("module-b")
+		b.Route("/module").GetMapping("/dup", func(ctx SpringWeb.WebContext) {})
+
+		ctx := &defaultApplicationContext{SpringContext: SpringCore.NewDefaultSpringContext()}
+		a.apply(ctx)
+		defer func() {
+			for key, mapping := range DefaultWebMapping.Mappings {
+				if mapping.Path() == "/module/dup" {
+					delete(DefaultWebMapping.Mappings, key)
+				}
+			}
+		}()
+
+		assert.Panic(t, func() {
+			b.apply(ctx)
+		}, "duplicate registration, mapping: ")
+	})
+}
@@ -18,19 +18,43 @@ package SpringBoot
 
 import (
 	"bytes"
+	"fmt"
 	"github.com/go-spring/go-spring-parent/spring-utils"
 	"github.com/magiconair/properties"
 	"github.com/spf13/viper"
 	"io/ioutil"
 	"os"
 	"sort"
+	"strings"
 )
 
-// configReaders 各种格式配置文件的读取器集合
-var configReaders = map[string]ConfigReader{
-	".properties": new(PropertiesReader),
-	".yaml":       &ViperReader{"yaml"},
-	".toml":       &ViperReader{"toml"},
+func init() {
+	RegisterConfigReader(".properties", new(PropertiesReader))
+	RegisterConfigReader(".yaml", &ViperReader{"yaml"})
+	RegisterConfigReader(".yml", &ViperReader{"yaml"})
+	RegisterConfigReader(".toml", &ViperReader{"toml"})
+	RegisterConfigReader(".json", &ViperReader{"json"})
+	RegisterConfigReader(".hcl", &ViperReader{"hcl"})
+}
+
+// configReaders 各种格式配置文件的读取器集合，key 是扩展名。
+var configReaders = make(map[string]ConfigReader)
+
+// configReaderExts 配置文件的扩展名，按照注册的顺序排列。同名的配置文件按照该顺序
+// 加载，后加载的覆盖先加载的。
+var configReaderExts []string
+
+// RegisterConfigReader 注册配置文件的读取器，ext 是带 . 的扩展名，如 ".ini"。注册
+// 的读取器用于配置目录、ConfigMap、configtree 以及导入的配置文件，重复注册会 panic。
+func RegisterConfigReader(ext string, reader ConfigReader) {
+	if !strings.HasPrefix(ext, ".") || len(ext) < 2 {
+		panic(fmt.Errorf("invalid config reader ext \"%s\"", ext))
+	}
+	if _, ok := configReaders[ext]; ok {
+		panic(fmt.Errorf("duplicate registration, config reader: \"%s\"", ext))
+	}
+	configReaders[ext] = reader
+	configReaderExts = append(configReaderExts, ext)
 }
 
 type ConfigReader interface {
@@ -38,6 +62,12 @@ type ConfigReader interface {
 	ReadBuffer(buffer []byte, out map[string]interface{})
 }
 
+// keyLocator 能够定位属性名所在行号的读取器
+type keyLocator interface {
+	// locateKeys 返回小写的属性名到行号的映射，行号从 1 开始。
+	locateKeys(buffer []byte) map[string]int
+}
+
 // PropertiesReader 读取 properties 格式的配置文件
 type PropertiesReader struct{}
 
@@ -61,7 +91,36 @@ func (r *PropertiesReader) ReadBuffer(buffer []byte, out map[string]interface{})
 	}
 }
 
-// ViperReader 读取 yaml、toml 等格式的配置文件
+// locateKeys 返回小写的属性名到行号的映射，行号从 1 开始。
+func (r *PropertiesReader) locateKeys(buffer []byte) map[string]int {
+	result := make(map[string]int)
+	continued := false
+
+	for i, line := range strings.Split(string(buffer), "\n") {
+		content := strings.TrimSpace(line)
+
+		// 跳过上一行的续行
+		if continued {
+			continued = strings.HasSuffix(content, "\\")
+			continue
+		}
+
+		if content == "" || content[0] == '#' || content[0] == '!' {
+			continue
+		}
+
+		continued = strings.HasSuffix(content, "\\")
+
+		end := strings.IndexAny(content, "=: \t")
+		if end < 0 {
+			end = len(content)
+		}
+		result[strings.ToLower(content[:end])] = i + 1
+	}
+	return result
+}
+
+// ViperReader 读取 yaml、toml、json、hcl 等格式的配置文件
 type ViperReader struct {
 	fileType string // yaml、toml 等
 }
@@ -73,14 +132,60 @@ func (r *ViperReader) readViper(v *viper.Viper, out map[string]interface{}) {
 
 	for _, key := range keys {
 		val := v.Get(key)
+		if r.fileType == "hcl" {
+			flattenHclValue(key, val, out)
+		} else {
+			out[key] = val
+		}
+	}
+}
+
+// flattenHclValue hcl 的块被解析为 []map[string]interface{}，viper 不会展开，这里
+// 将只出现一次的块展开为以 . 连接的属性名，重复出现的块作为对象列表。
+func flattenHclValue(key string, val interface{}, out map[string]interface{}) {
+	switch v := val.(type) {
+	case []map[string]interface{}:
+		if len(v) == 1 {
+			flattenHclValue(key, v[0], out)
+		} else {
+			out[key] = normalizeHclValue(v)
+		}
+	case map[string]interface{}:
+		for k, x := range v {
+			flattenHclValue(key+"."+strings.ToLower(k), x, out)
+		}
+	default:
 		out[key] = val
 	}
 }
 
+// normalizeHclValue 将 hcl 的块转换为 yaml 等格式相同的 map 和列表
+func normalizeHclValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case []map[string]interface{}:
+		if len(v) == 1 {
+			return normalizeHclValue(v[0])
+		}
+		list := make([]interface{}, len(v))
+		for i, m := range v {
+			list[i] = normalizeHclValue(m)
+		}
+		return list
+	case map[string]interface{}:
+		m := make(map[string]interface{})
+		for k, x := range v {
+			m[strings.ToLower(k)] = normalizeHclValue(x)
+		}
+		return m
+	}
+	return val
+}
+
 func (r *ViperReader) ReadFile(filename string, out map[string]interface{}) {
 
 	v := viper.New()
 	v.SetConfigFile(filename)
+	v.SetConfigType(r.fileType)
 
 	err := v.ReadInConfig()
 	SpringUtils.Panic(err).When(err != nil)
@@ -98,3 +203,195 @@ func (r *ViperReader) ReadBuffer(buffer []byte, out map[string]interface{}) {
 
 	r.readViper(v, out)
 }
+
+// splitDocuments 使用 --- 将 yaml 拆分为多个文档，忽略空白的文档，其他格式不拆分。
+func (r *ViperReader) splitDocuments(buffer []byte) (docs [][]byte, offsets []int) {
+
+	if r.fileType != "yaml" {
+		return [][]byte{buffer}, []int{0}
+	}
+
+	lines := strings.Split(string(buffer), "\n")
+	start := 0
+
+	flush := func(end int) {
+		if doc := strings.Join(lines[start:end], "\n"); strings.TrimSpace(doc) != "" {
+			docs = append(docs, []byte(doc))
+			offsets = append(offsets, start)
+		}
+	}
+
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "---" || strings.HasPrefix(line, "--- ") {
+			flush(i)
+			start = i + 1
+		}
+	}
+	flush(len(lines))
+	return docs, offsets
+}
+
+// locateKeys 返回小写的属性名到行号的映射，行号从 1 开始。
+func (r *ViperReader) locateKeys(buffer []byte) map[string]int {
+	switch r.fileType {
+	case "yaml":
+		return locateYamlKeys(buffer)
+	case "toml":
+		return locateTomlKeys(buffer)
+	case "json":
+		return locateJsonKeys(buffer)
+	}
+	return nil
+}
+
+// locateYamlKeys 根据缩进还原 yaml 的层级结构，返回属性名到行号的映射。
+func locateYamlKeys(buffer []byte) map[string]int {
+
+	type level struct {
+		indent int
+		key    string
+	}
+
+	var stack []level
+	result := make(map[string]int)
+	blockIndent := -1 // 多行文本所属属性的缩进，-1 表示不在多行文本中
+
+	for i, line := range strings.Split(string(buffer), "\n") {
+		content := strings.TrimSpace(line)
+		if content == "" || strings.HasPrefix(content, "#") {
+			continue
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		// 跳过多行文本的内容
+		if blockIndent >= 0 {
+			if indent > blockIndent {
+				continue
+			}
+			blockIndent = -1
+		}
+
+		if content == "---" {
+			stack = nil
+			continue
+		}
+
+		// 列表元素没有独立的属性名
+		if content == "-" || strings.HasPrefix(content, "- ") {
+			continue
+		}
+
+		j := strings.Index(content, ":")
+		if j <= 0 || (j < len(content)-1 && content[j+1] != ' ') {
+			continue
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+
+		key := strings.ToLower(strings.Trim(content[:j], `"'`))
+		stack = append(stack, level{indent, key})
+
+		keys := make([]string, len(stack))
+		for k, l := range stack {
+			keys[k] = l.key
+		}
+		result[strings.Join(keys, ".")] = i + 1
+
+		if value := strings.TrimSpace(content[j+1:]); strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">") {
+			blockIndent = indent
+		}
+	}
+	return result
+}
+
+// locateTomlKeys 根据表头还原 toml 的层级结构，返回属性名到行号的映射。
+func locateTomlKeys(buffer []byte) map[string]int {
+	prefix := ""
+	result := make(map[string]int)
+
+	for i, line := range strings.Split(string(buffer), "\n") {
+		content := strings.TrimSpace(line)
+		if content == "" || strings.HasPrefix(content, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(content, "[") {
+			table := strings.TrimSpace(strings.Trim(content, "[]"))
+			prefix = strings.ToLower(table) + "."
+			continue
+		}
+
+		if j := strings.Index(content, "="); j > 0 {
+			key := strings.Trim(strings.TrimSpace(content[:j]), `"'`)
+			result[prefix+strings.ToLower(key)] = i + 1
+		}
+	}
+	return result
+}
+
+// locateJsonKeys 根据对象的嵌套关系还原 json 的层级结构，返回属性名到行号的映射。
+// 数组中的对象没有独立的属性名，其中的属性名被忽略。
+func locateJsonKeys(buffer []byte) map[string]int {
+
+	type container struct {
+		array bool   // 是否是数组
+		key   string // 对象中正在解析的属性名
+	}
+
+	var stack []container
+	result := make(map[string]int)
+	line := 1
+
+	for i := 0; i < len(buffer); i++ {
+		switch c := buffer[i]; c {
+		case '\n':
+			line++
+		case '{', '[':
+			stack = append(stack, container{array: c == '['})
+		case '}', ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case '"':
+			start, keyLine := i+1, line
+			for i++; i < len(buffer) && buffer[i] != '"'; i++ {
+				if buffer[i] == '\\' {
+					i++
+				} else if buffer[i] == '\n' {
+					line++
+				}
+			}
+			if i >= len(buffer) {
+				return result
+			}
+
+			// 后面紧跟 : 的字符串是属性名
+			j := i + 1
+			for j < len(buffer) && strings.IndexByte(" \t\r\n", buffer[j]) >= 0 {
+				j++
+			}
+			if j >= len(buffer) || buffer[j] != ':' || len(stack) == 0 {
+				continue
+			}
+
+			stack[len(stack)-1].key = strings.ToLower(string(buffer[start:i]))
+
+			var keys []string
+			for _, s := range stack {
+				if s.array {
+					keys = nil
+					break
+				}
+				keys = append(keys, s.key)
+			}
+			if keys != nil {
+				result[strings.Join(keys, ".")] = keyLine
+			}
+		}
+	}
+	return result
+}
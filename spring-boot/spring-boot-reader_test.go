@@ -0,0 +1,123 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringBoot
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+)
+
+// iniReader 测试用的读取器，只支持 key=value 的形式
+type iniReader struct{}
+
+func (r *iniReader) ReadFile(filename string, out map[string]interface{}) {
+	buffer, _ := ioutil.ReadFile(filename)
+	r.ReadBuffer(buffer, out)
+}
+
+func (r *iniReader) ReadBuffer(buffer []byte, out map[string]interface{}) {
+	for _, line := range strings.Split(string(buffer), "\n") {
+		if ss := strings.SplitN(line, "=", 2); len(ss) == 2 {
+			out[strings.TrimSpace(ss[0])] = strings.TrimSpace(ss[1])
+		}
+	}
+}
+
+func TestConfigReaders(t *testing.T) {
+
+	t.Run("formats", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "config-readers")
+		assert.Equal(t, err, nil)
+		defer os.RemoveAll(dir)
+
+		writeConfigTree(t, dir, map[string]string{
+			"application.json":     "{\n  \"web\": {\"server\": {\"port\": 8080}},\n  \"name\": \"json\"\n}\n",
+			"application.yml":      "db:\n  url: mysql://yml\n---\nspring.config.activate.on-profile: test\ndb:\n  url: mysql://test\n",
+			"application.hcl":      "redis {\n  host = \"127.0.0.1\"\n  port = 6379\n}\nservers {\n  host = \"a\"\n}\nservers {\n  host = \"b\"\n}\n",
+			"application-test.hcl": "name = \"hcl-test\"\n",
+		})
+
+		p := NewDefaultPropertySource(dir)
+		result := p.Load("", nil)
+		assert.Equal(t, result.GetProperty("web.server.port"), float64(8080))
+		assert.Equal(t, result.GetProperty("name"), "json")
+		assert.Equal(t, result.GetProperty("db.url"), "mysql://yml")
+		assert.Equal(t, result.GetProperty("redis.host"), "127.0.0.1")
+		assert.Equal(t, result.GetProperty("redis.port"), 6379)
+		assert.Equal(t, result.GetProperty("servers"), []interface{}{
+			map[string]interface{}{"host": "a"},
+			map[string]interface{}{"host": "b"},
+		})
+
+		origin, _ := result.GetPropertyOrigin("web.server.port")
+		assert.Equal(t, origin.String(), filepath.Join(dir, "application.json")+":2")
+
+		result = p.Load("test", nil)
+		assert.Equal(t, result.GetProperty("db.url"), "mysql://test")
+		assert.Equal(t, result.GetProperty("name"), "hcl-test")
+	})
+
+	t.Run("config-map", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "config-readers")
+		assert.Equal(t, err, nil)
+		defer os.RemoveAll(dir)
+
+		writeConfigTree(t, dir, map[string]string{
+			"config-map.yaml": "data:\n  application.json: |-\n    {\"name\": \"config-map\"}\n",
+		})
+
+		p := NewConfigMapPropertySource(filepath.Join(dir, "config-map.yaml"))
+		assert.Equal(t, p.Load("", nil).GetProperty("name"), "config-map")
+	})
+
+	t.Run("register", func(t *testing.T) {
+		exts := configReaderExts
+		defer func() {
+			configReaderExts = exts
+			delete(configReaders, ".ini")
+		}()
+
+		RegisterConfigReader(".ini", new(iniReader))
+
+		dir, err := ioutil.TempDir("", "config-readers")
+		assert.Equal(t, err, nil)
+		defer os.RemoveAll(dir)
+
+		writeConfigTree(t, dir, map[string]string{
+			"application.properties": "name=properties\nlevel=info\n",
+			"application.ini":        "name = ini\n",
+		})
+
+		// 后注册的格式覆盖先注册的格式
+		result := NewDefaultPropertySource(dir).Load("", nil)
+		assert.Equal(t, result.GetProperty("name"), "ini")
+		assert.Equal(t, result.GetProperty("level"), "info")
+
+		assert.Panic(t, func() {
+			RegisterConfigReader(".ini", new(iniReader))
+		}, `duplicate registration, config reader: ".ini"`)
+
+		assert.Panic(t, func() {
+			RegisterConfigReader("ini", new(iniReader))
+		}, `invalid config reader ext "ini"`)
+	})
+}
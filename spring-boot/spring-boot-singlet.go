@@ -18,6 +18,9 @@ package SpringBoot
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/url"
 	"time"
 
 	"github.com/go-spring/go-spring/boot-starter"
@@ -27,6 +30,12 @@ import (
 // ctx 全局的 SpringContext 变量
 var ctx = SpringCore.NewDefaultSpringContext()
 
+// defaultConfig 内部默认配置，优先级最低
+var defaultConfig = SpringCore.NewDefaultProperties()
+
+// globalApp 基于全局上下文的 application
+var globalApp *application
+
 // expectSysProperties 期望从系统环境变量中获取到的属性
 var expectSysProperties = []string{`.*`}
 
@@ -35,13 +44,20 @@ func ExpectSysProperties(pattern ...string) {
 	expectSysProperties = pattern
 }
 
+// newGlobalApplication 创建基于全局上下文、全局模块和全局默认配置的 application
+func newGlobalApplication(configLocation ...string) *application {
+	app := newApplication(&defaultApplicationContext{
+		SpringContext: ctx,
+	}, configLocation...)
+	app.modules = modules
+	app.defaults = defaultConfig
+	globalApp = app
+	return app
+}
+
 // RunApplication 快速启动 SpringBoot 应用
 func RunApplication(configLocation ...string) {
-	BootStarter.Run(newApplication(
-		&defaultApplicationContext{
-			SpringContext: ctx,
-		}, configLocation...),
-	)
+	BootStarter.Run(newGlobalApplication(configLocation...))
 }
 
 // AppBuilder application 的构造器
@@ -55,11 +71,7 @@ func NewApplication() *AppBuilder {
 
 // Run 快速启动 SpringBoot 应用
 func (cfg *AppBuilder) Run(configLocation ...string) {
-	BootStarter.Run(newApplication(
-		&defaultApplicationContext{
-			SpringContext: ctx,
-		}, configLocation...),
-	)
+	BootStarter.Run(newGlobalApplication(configLocation...))
 }
 
 // Exit 退出 SpringBoot 应用
@@ -67,18 +79,77 @@ func Exit() {
 	BootStarter.Exit()
 }
 
+// Reload 重新加载属性值，属性值发生变化时重新绑定可刷新 Bean 的属性值并发布
+// 属性变化事件。加载或者绑定失败时返回错误，属性值和 Bean 保持不变。运行环境
+// 不能通过重新加载改变，见 application.Reload。
+func Reload() error {
+	if globalApp == nil {
+		return errors.New("application not started")
+	}
+	return globalApp.Reload()
+}
+
+// GetArgs 返回命令行中的位置参数，包括 -- 之后的所有参数。
+func GetArgs() []string {
+	if globalApp == nil || globalApp.cmdLine == nil {
+		return nil
+	}
+	return append([]string{}, globalApp.cmdLine.args...)
+}
+
+// SetConfigWatchInterval 设置动态刷新时配置文件的轮询间隔，默认 5 秒。
+func SetConfigWatchInterval(d time.Duration) {
+	fileWatchInterval = d
+}
+
+//////////////// Default Properties ////////////////////////
+
+// SetDefaultProperty 设置内部默认属性值，它的优先级最低，属性名称统一转成小写。
+func SetDefaultProperty(key string, value interface{}) {
+	defaultConfig.SetProperty(key, value)
+}
+
+// SetDefaultProperties 批量设置内部默认属性值，它的优先级最低，属性名称统一转成小写。
+func SetDefaultProperties(properties map[string]interface{}) {
+	for k, v := range properties {
+		defaultConfig.SetProperty(k, v)
+	}
+}
+
+// ReadDefaultProperties 读取内部默认配置，比如编译进程序的配置文件内容，
+// ext 是配置文件的扩展名，如 ".properties"、".yaml"、".json"，见 RegisterConfigReader。
+func ReadDefaultProperties(buffer []byte, ext string) {
+	reader, ok := configReaders[ext]
+	if !ok {
+		panic(fmt.Errorf("unsupported config type \"%s\"", ext))
+	}
+	result := make(map[string]interface{})
+	reader.ReadBuffer(buffer, result)
+	SetDefaultProperties(result)
+}
+
 //////////////// SpringContext ////////////////////////
 
-// GetProfile 返回运行环境
+// GetProfile 返回逗号分隔的运行环境
 func GetProfile() string {
 	return ctx.GetProfile()
 }
 
-// SetProfile 设置运行环境
+// SetProfile 设置运行环境，多个运行环境使用逗号分隔
 func SetProfile(profile string) {
 	ctx.SetProfile(profile)
 }
 
+// GetProfiles 返回所有的运行环境
+func GetProfiles() []string {
+	return ctx.GetProfiles()
+}
+
+// SetProfiles 设置运行环境
+func SetProfiles(profiles ...string) {
+	ctx.SetProfiles(profiles...)
+}
+
 // AllAccess 返回是否允许访问私有字段
 func AllAccess() bool {
 	return ctx.AllAccess()
@@ -89,6 +160,18 @@ func SetAllAccess(allAccess bool) {
 	ctx.SetAllAccess(allAccess)
 }
 
+// RegisterTypeConverter 注册上下文的类型转换器，函数原型 func(string)type 或者
+// func(string)(type,error)，优先于全局的类型转换器。
+func RegisterTypeConverter(fn interface{}) {
+	ctx.RegisterTypeConverter(fn)
+}
+
+// RegisterConverter 注册上下文的具名类型转换器，通过 conv 标签使用，优先于全局
+// 的具名类型转换器，重复注册会 panic。
+func RegisterConverter(name string, fn interface{}) {
+	ctx.RegisterConverter(name, fn)
+}
+
 // RegisterBean 注册单例 Bean，不指定名称，重复注册会 panic。
 func RegisterBean(bean interface{}) *SpringCore.BeanDefinition {
 	return ctx.RegisterBean(bean)
@@ -99,6 +182,12 @@ func RegisterNameBean(name string, bean interface{}) *SpringCore.BeanDefinition
 	return ctx.RegisterNameBean(name, bean)
 }
 
+// RegisterConfigurationProperties 注册配置属性 Bean，bean 必须是结构体指针，字段
+// 的 value 标签使用相对于 prefix 的属性名，Bean 的名称是 prefix，重复注册会 panic。
+func RegisterConfigurationProperties(prefix string, bean interface{}) *SpringCore.BeanDefinition {
+	return ctx.RegisterConfigurationProperties(prefix, bean)
+}
+
 // RegisterBeanFn 注册单例构造函数 Bean，不指定名称，重复注册会 panic。
 func RegisterBeanFn(fn interface{}, tags ...string) *SpringCore.BeanDefinition {
 	return ctx.RegisterBeanFn(fn, tags...)
@@ -208,11 +297,71 @@ func GetTimeProperty(keys ...string) time.Time {
 	return ctx.GetTimeProperty(keys...)
 }
 
+// GetBoolPropertyE 返回布尔型属性值，属性值不存在时返回 SpringCore.ErrPropertyNotFound 错误。
+func GetBoolPropertyE(key string) (bool, error) {
+	return ctx.GetBoolPropertyE(key)
+}
+
+// GetIntPropertyE 返回有符号整型属性值，属性值不存在时返回 SpringCore.ErrPropertyNotFound 错误。
+func GetIntPropertyE(key string) (int64, error) {
+	return ctx.GetIntPropertyE(key)
+}
+
+// GetUintPropertyE 返回无符号整型属性值，属性值不存在时返回 SpringCore.ErrPropertyNotFound 错误。
+func GetUintPropertyE(key string) (uint64, error) {
+	return ctx.GetUintPropertyE(key)
+}
+
+// GetFloatPropertyE 返回浮点型属性值，属性值不存在时返回 SpringCore.ErrPropertyNotFound 错误。
+func GetFloatPropertyE(key string) (float64, error) {
+	return ctx.GetFloatPropertyE(key)
+}
+
+// GetStringPropertyE 返回字符串型属性值，属性值不存在时返回 SpringCore.ErrPropertyNotFound 错误。
+func GetStringPropertyE(key string) (string, error) {
+	return ctx.GetStringPropertyE(key)
+}
+
+// GetDurationPropertyE 返回Duration 类型属性值，属性值不存在时返回 SpringCore.ErrPropertyNotFound 错误。
+func GetDurationPropertyE(key string) (time.Duration, error) {
+	return ctx.GetDurationPropertyE(key)
+}
+
+// GetTimePropertyE 返回Time 类型的属性值，属性值不存在时返回 SpringCore.ErrPropertyNotFound 错误。
+func GetTimePropertyE(key string) (time.Time, error) {
+	return ctx.GetTimePropertyE(key)
+}
+
+// GetStringSlicePropertyE 返回字符串列表类型的属性值，字符串按照逗号切割，属性值不存在时返回 SpringCore.ErrPropertyNotFound 错误。
+func GetStringSlicePropertyE(key string) ([]string, error) {
+	return ctx.GetStringSlicePropertyE(key)
+}
+
+// GetStringMapPropertyE 返回map[string]string 类型的属性值，由以 key 为前缀的属性值组成，属性值不存在时返回 SpringCore.ErrPropertyNotFound 错误。
+func GetStringMapPropertyE(key string) (map[string]string, error) {
+	return ctx.GetStringMapPropertyE(key)
+}
+
+// GetByteSizePropertyE 返回字节数，如 64KB、1.5MB、2GiB，使用 1024 进制，属性值不存在时返回 SpringCore.ErrPropertyNotFound 错误。
+func GetByteSizePropertyE(key string) (int64, error) {
+	return ctx.GetByteSizePropertyE(key)
+}
+
+// GetURLPropertyE 返回URL 类型的属性值，URL 必须包含 scheme，属性值不存在时返回 SpringCore.ErrPropertyNotFound 错误。
+func GetURLPropertyE(key string) (*url.URL, error) {
+	return ctx.GetURLPropertyE(key)
+}
+
 // GetDefaultProperty 返回属性值，如果没有找到则使用指定的默认值，属性名称统一转成小写。
 func GetDefaultProperty(key string, def interface{}) (interface{}, bool) {
 	return ctx.GetDefaultProperty(key, def)
 }
 
+// GetPropertyOrigin 返回属性值的来源，如配置文件及行号、环境变量的名称等。
+func GetPropertyOrigin(key string) (SpringCore.PropertyOrigin, bool) {
+	return ctx.GetPropertyOrigin(key)
+}
+
 // SetProperty 设置属性值，属性名称统一转成小写。
 func SetProperty(key string, value interface{}) {
 	ctx.SetProperty(key, value)
@@ -228,6 +377,11 @@ func GetProperties() map[string]interface{} {
 	return ctx.GetProperties()
 }
 
+// GetPropertyName 返回设置属性值时使用的属性名，保留原始的大小写。
+func GetPropertyName(key string) string {
+	return ctx.GetPropertyName(key)
+}
+
 // BindProperty 根据类型获取属性值，属性名称统一转成小写。
 func BindProperty(key string, i interface{}) {
 	ctx.BindProperty(key, i)
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringBoot
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-spring/go-spring-parent/spring-logger"
+	"github.com/go-spring/go-spring/spring-core"
+)
+
+const (
+	SpringConfigStrictMode    = "spring.config.strict.mode" // 未使用属性的处理方式，off、warn 或者 fail
+	SPRING_CONFIG_STRICT_MODE = "SPRING_CONFIG_STRICT_MODE"
+
+	SpringConfigStrictIgnore = "spring.config.strict.ignore" // 严格模式忽略的属性名前缀，多个前缀使用逗号分隔
+)
+
+// 严格模式的处理方式
+const (
+	strictModeOff  = "off"  // 不检查未使用的属性
+	strictModeWarn = "warn" // 打印未使用的属性
+	strictModeFail = "fail" // 存在未使用的属性时启动失败
+)
+
+// strictSkippedLayers 严格模式不检查的配置层，环境变量和内部默认配置不是应用的配置。
+var strictSkippedLayers = map[string]bool{
+	"system-env":     true,
+	"default-config": true,
+}
+
+// loadingKeys 在加载配置阶段使用的属性，它们不会从上下文中读取。
+var loadingKeys = []string{
+	SpringProfile,
+	SpringProfilesInclude,
+	SpringProfilesGroup,
+	SpringConfigImport,
+}
+
+// unusedProperty 未使用的属性及其来源
+type unusedProperty struct {
+	key    string
+	origin SpringCore.PropertyOrigin
+}
+
+// findUnusedProperties 返回完成依赖注入之后仍未被读取过的属性，ignore 是忽略的属性名前缀。
+func findUnusedProperties(ctx SpringCore.SpringContext, ignore []string) []unusedProperty {
+
+	var prefixes []string
+	for _, s := range append(ignore, loadingKeys...) {
+		prefixes = append(prefixes, SpringCore.CanonicalPropertyKey(s))
+	}
+
+	var result []unusedProperty
+	for _, key := range ctx.GetUnusedProperties() {
+		origin, _ := ctx.GetPropertyOrigin(key)
+		if strictSkippedLayers[origin.Layer] {
+			continue
+		}
+
+		ignored := false
+		canonical := SpringCore.CanonicalPropertyKey(key)
+		for _, prefix := range prefixes {
+			if canonical == prefix || strings.HasPrefix(canonical, prefix+".") {
+				ignored = true
+				break
+			}
+		}
+
+		if !ignored {
+			result = append(result, unusedProperty{key: key, origin: origin})
+		}
+	}
+	return result
+}
+
+// checkUnusedProperties 严格模式下检查从未被读取过的属性，比如拼写错误的属性名，
+// 根据 spring.config.strict.mode 打印警告或者启动失败。在启动器和应用启动事件
+// 中才读取的属性也会被认为未使用，可以通过 spring.config.strict.ignore 忽略。
+func (app *application) checkUnusedProperties() {
+
+	keys := []string{SpringConfigStrictMode, SPRING_CONFIG_STRICT_MODE}
+	mode := strings.ToLower(app.appCtx.GetStringProperty(keys...))
+
+	switch mode {
+	case "", strictModeOff:
+		return
+	case strictModeWarn, strictModeFail:
+	default:
+		panic(fmt.Errorf("invalid %s \"%s\"", SpringConfigStrictMode, mode))
+	}
+
+	ignore := propertyList(app.appCtx.GetProperty(SpringConfigStrictIgnore))
+	unused := findUnusedProperties(app.appCtx, ignore)
+	if len(unused) == 0 {
+		return
+	}
+
+	if mode == strictModeWarn {
+		for _, u := range unused {
+			SpringLogger.Warnf("unused property %s %s", u.key, u.origin)
+		}
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("found unused properties:")
+	for _, u := range unused {
+		sb.WriteString(fmt.Sprintf("\n  %s %s", u.key, u.origin))
+	}
+	panic(errors.New(sb.String()))
+}
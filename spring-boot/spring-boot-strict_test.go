@@ -0,0 +1,88 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringBoot
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-spring/go-spring/spring-core"
+	"github.com/magiconair/properties/assert"
+)
+
+type strictServerConfig struct {
+	Port int `value:"${web.server.port}"`
+}
+
+func TestStrictMode(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "strict-mode")
+	assert.Equal(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	writeConfigTree(t, dir, map[string]string{
+		"application.properties": "spring.profile=dev\nspring.profiles.group.dev=local\n" +
+			"web.server.port=8080\nweb.sever.host=localhost\nfeature.flag=true\n",
+		"application-local.yaml": "debug: true\n",
+	})
+
+	newApp := func(mode string) *application {
+		app := newApplication(&defaultApplicationContext{
+			SpringContext: SpringCore.NewDefaultSpringContext(),
+		}, dir)
+		app.appCtx.SetProperty("application-event.collection", "[]?")
+		app.appCtx.SetProperty("command-line-runner.collection", "[]?")
+		app.appCtx.SetProperty(SpringConfigStrictMode, mode)
+		// 忽略 go test 自己的命令行参数
+		app.appCtx.SetProperty(SpringConfigStrictIgnore, "feature,test")
+		app.appCtx.RegisterBean(new(strictServerConfig))
+		return app
+	}
+
+	t.Run("unused", func(t *testing.T) {
+		app := newApp("warn")
+		app.Start()
+		defer app.ShutDown()
+
+		unused := findUnusedProperties(app.appCtx, []string{"feature", "test"})
+		assert.Equal(t, len(unused), 2)
+		assert.Equal(t, unused[0].key, "debug")
+		assert.Equal(t, unused[0].origin.Layer, "profile-config")
+		assert.Equal(t, unused[1].key, "web.sever.host")
+		assert.Equal(t, unused[1].origin.String(), filepath.Join(dir, "application.properties")+":4 [app-config]")
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		app := newApp("fail")
+		defer app.ShutDown()
+
+		assert.Panic(t, func() {
+			app.Start()
+		}, "found unused properties:\n  debug .*application-local.yaml:1 \\[profile-config\\]\n  web.sever.host .*application.properties:4 \\[app-config\\]$")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		app := newApp("strict")
+		defer app.ShutDown()
+
+		assert.Panic(t, func() {
+			app.Start()
+		}, "invalid spring.config.strict.mode \"strict\"")
+	})
+}
@@ -184,6 +184,9 @@ func TestRunApplication(t *testing.T) {
 
 	SpringBoot.SetProperty("db.url", "root:root@/information_schema?charset=utf8&parseTime=True&loc=Local")
 
+	// WebServerConfig 是前缀为 web.server 的配置属性 Bean
+	SpringBoot.SetProperty("web.server.ssl.port", 9443)
+
 	dir, _ := os.Getwd()
 	SpringBoot.SetProperty("static.root", path.Dir(dir))
 
@@ -325,6 +328,11 @@ type MyRunner struct {
 
 func (_ *MyRunner) Run(appCtx SpringBoot.ApplicationContext) {
 
+	var cfg *WebStarter.WebServerConfig
+	if !appCtx.GetBean(&cfg) || cfg.Port != 8080 || cfg.SSLPort != 9443 {
+		panic(fmt.Errorf("web server config isn't bound with prefix web.server: %+v", cfg))
+	}
+
 	appCtx.SafeGoroutine(func() {
 		SpringLogger.Trace("get all properties:")
 		for k, v := range appCtx.GetProperties() {
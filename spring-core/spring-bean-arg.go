@@ -39,6 +39,8 @@ type fnStringBindingArg struct {
 	fnTags [][]string // 可能包含可变参数
 
 	withReceiver bool // 函数是否包含接收者，也可以假装第一个参数是接收者
+
+	bound map[int]reflect.Value // 创建 Bean 之前已经绑定的结构体参数，参数序号 -> 参数值
 }
 
 // newFnStringBindingArg fnStringBindingArg 的构造函数，所有 tag 必须同时有或者同时没有序号。
@@ -111,7 +113,15 @@ func newFnStringBindingArg(fnType reflect.Type, withReceiver bool, tags []string
 		}
 	}
 
-	return &fnStringBindingArg{fnType, fnTags, withReceiver}
+	return &fnStringBindingArg{fnType: fnType, fnTags: fnTags, withReceiver: withReceiver}
+}
+
+// argType 返回第 i 个参数的类型，不包括接收者。
+func (arg *fnStringBindingArg) argType(i int) reflect.Type {
+	if arg.withReceiver {
+		return arg.fnType.In(i + 1)
+	}
+	return arg.fnType.In(i)
 }
 
 // Get 获取函数参数的绑定值，fileLine 是函数所在文件及其行号，日志使用
@@ -130,12 +140,7 @@ func (arg *fnStringBindingArg) Get(assembly beanAssembly, fileLine string) []ref
 
 	for i, tags := range arg.fnTags {
 
-		var it reflect.Type
-		if arg.withReceiver {
-			it = fnType.In(i + 1)
-		} else {
-			it = fnType.In(i)
-		}
+		it := arg.argType(i)
 
 		if variadic && i == numIn-1 { // 可变参数
 			et := it.Elem() // 数组类型
@@ -144,6 +149,8 @@ func (arg *fnStringBindingArg) Get(assembly beanAssembly, fileLine string) []ref
 				arg.getArgValue(ev, tag, assembly, fileLine)
 				result = append(result, ev)
 			}
+		} else if bv, ok := arg.bound[i]; ok { // 已经在 validateBeans 中完成了绑定
+			result = append(result, bv)
 		} else {
 			var tag string
 			if len(tags) > 0 {
@@ -165,10 +172,17 @@ func (arg *fnStringBindingArg) getArgValue(v reflect.Value, tag string, assembly
 	SpringLogger.Tracef("get value %s", description)
 
 	if ctx := assembly.springContext(); IsValueType(v.Kind()) { // 值类型，采用属性绑定语法
+
+		// 没有标签的结构体参数优先使用同类型的配置属性 Bean
+		if tag == "" && v.Kind() == reflect.Struct && assembly.getConfigurationBean(v) {
+			SpringLogger.Tracef("get value success %s", description)
+			return
+		}
+
 		if tag == "" {
 			tag = "${}"
 		}
-		bindStructField(ctx, v, tag, bindOption{
+		assembly.bindStructField(v, tag, bindOption{
 			allAccess: ctx.AllAccess(),
 		})
 	} else { // 引用类型，采用对象注入语法
@@ -327,7 +341,7 @@ func (arg *optionArg) ConditionOnMatches(fn ConditionFunc) *optionArg {
 
 // ConditionOnProfile 设置一个 ProfileCondition
 func (arg *optionArg) ConditionOnProfile(profile string) *optionArg {
-	arg.cond.OnProperty(profile)
+	arg.cond.OnProfile(profile)
 	return arg
 }
 
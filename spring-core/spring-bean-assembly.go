@@ -31,6 +31,12 @@ import (
 type beanAssembly interface {
 	springContext() SpringContext
 
+	// bindStructField 使用上下文的属性值和类型转换器对字段进行属性绑定
+	bindStructField(v reflect.Value, tag string, opt bindOption)
+
+	// getConfigurationBean 使用同类型的配置属性 Bean 的值，找到返回 true 否则返回 false。
+	getConfigurationBean(v reflect.Value) bool
+
 	// wireStructField 对结构体的字段进行绑定
 	wireStructField(v reflect.Value, tag string, parent reflect.Value, field string)
 
@@ -72,6 +78,9 @@ func (s *wiringStack) path() (path string) {
 		w := e.Value.(beanDefinition)
 		path += fmt.Sprintf("=> %s ↩\n", w.Description())
 	}
+	if path == "" { // 校验属性值时没有正在注入的 Bean
+		return path
+	}
 	return path[:len(path)-1]
 }
 
@@ -95,6 +104,71 @@ func (assembly *defaultBeanAssembly) springContext() SpringContext {
 	return assembly.springCtx
 }
 
+// bindStructField 使用上下文的属性值和类型转换器对字段进行属性绑定
+func (assembly *defaultBeanAssembly) bindStructField(v reflect.Value, tag string, opt bindOption) {
+	val := &validation{}
+	opt.converters = assembly.springCtx.converters
+	opt.validation = val
+	bindStructField(assembly.springCtx, v, tag, opt)
+
+	if err := val.err(); err != nil {
+		panic(err)
+	}
+}
+
+// getConfigurationBean 使用同类型的配置属性 Bean 的值，并且确保配置属性 Bean 完成
+// 属性绑定，找到返回 true 否则返回 false，找到多个时 panic。
+func (assembly *defaultBeanAssembly) getConfigurationBean(v reflect.Value) bool {
+
+	found := assembly.springCtx.findConfigurationBean(v.Type())
+	if found == nil {
+		return false
+	}
+
+	assembly.wireBeanDefinition(found, false)
+	v.Set(found.Value().Elem())
+	return true
+}
+
+// findConfigurationBean 返回 t 类型的配置属性 Bean，找不到时返回 nil，找到多个时 panic。
+func (ctx *defaultSpringContext) findConfigurationBean(t reflect.Type) *BeanDefinition {
+
+	var found *BeanDefinition
+	pt := reflect.PtrTo(t)
+
+	for _, bd := range ctx.beanMap {
+		if _, ok := bd.getConfigPrefix(); !ok || bd.Type() != pt || bd.status == beanStatus_Deleted {
+			continue
+		}
+		if found != nil {
+			panic(fmt.Errorf("found 2 configuration properties beans of type %s", t.String()))
+		}
+		found = bd
+	}
+	return found
+}
+
+// bindConfigurationProperties 使用属性名前缀绑定配置属性 Bean 的所有字段
+func (assembly *defaultBeanAssembly) bindConfigurationProperties(v reflect.Value, prefix string, fieldName string) {
+
+	val := &validation{}
+	opt := bindOption{
+		propNamePrefix: prefix,
+		fullPropName:   prefix,
+		fieldName:      fieldName,
+		allAccess:      assembly.springCtx.AllAccess(),
+		converters:     assembly.springCtx.converters,
+		validation:     val,
+	}
+
+	bindStruct(assembly.springCtx, v, opt)
+	validateStruct(assembly.springCtx, v, prefix, opt)
+
+	if err := val.err(); err != nil {
+		panic(err)
+	}
+}
+
 // getBeanValue 获取符合要求的 Bean，并且确保 Bean 完成自动注入过程，结果最多有一个，否则 panic，当允许结果为空时返回 false，否则 panic
 func (assembly *defaultBeanAssembly) getBeanValue(v reflect.Value, tag SingletonTag, parent reflect.Value, field string) bool {
 
@@ -476,6 +550,17 @@ func (assembly *defaultBeanAssembly) wireObjectBean(bd beanDefinition, onlyAutoW
 			sv := bd.Value()
 			ev := sv.Elem()
 
+			// 属性值已经在 validateBeans 中完成了绑定和校验
+			if bd.isValueBound() {
+				onlyAutoWire = true
+			}
+
+			// 配置属性 Bean 使用属性名前缀一次绑定所有的字段
+			if prefix, ok := bd.getConfigPrefix(); ok && !onlyAutoWire {
+				assembly.bindConfigurationProperties(ev, prefix, etName)
+				onlyAutoWire = true
+			}
+
 			// 遍历 Bean 的每个字段，按照 tag 进行注入
 			for i := 0; i < et.NumField(); i++ {
 
@@ -490,9 +575,12 @@ func (assembly *defaultBeanAssembly) wireObjectBean(bd beanDefinition, onlyAutoW
 				if !onlyAutoWire { // 防止 value 再次解析
 					if tag, ok := ft.Tag.Lookup("value"); ok {
 						fieldOnlyAutoWire = true
-						bindStructField(assembly.springCtx, fv, tag, bindOption{
-							allAccess: assembly.springCtx.AllAccess(),
-							fieldName: fieldName,
+						assembly.bindStructField(fv, tag, bindOption{
+							allAccess:    assembly.springCtx.AllAccess(),
+							fieldName:    fieldName,
+							preserveCase: ft.Tag.Get("case") == "preserve",
+							conv:         ft.Tag.Get("conv"),
+							validate:     ft.Tag.Get("validate"),
 						})
 					}
 				}
@@ -519,7 +607,7 @@ func (assembly *defaultBeanAssembly) wireObjectBean(bd beanDefinition, onlyAutoW
 						b.file = bd.getFile()
 						b.line = bd.getLine()
 						fbd := &fieldBeanDefinition{b, fieldName}
-						assembly.wireBeanDefinition(fbd, fieldOnlyAutoWire)
+						assembly.wireBeanDefinition(fbd, fieldOnlyAutoWire || onlyAutoWire)
 					}
 				}
 			}
@@ -599,7 +687,7 @@ func (assembly *defaultBeanAssembly) wireStructField(v reflect.Value, tag string
 	if strings.HasPrefix(tag, "${") {
 		s := ""
 		sv := reflect.ValueOf(&s).Elem()
-		bindStructField(assembly.springCtx, sv, tag, bindOption{})
+		assembly.bindStructField(sv, tag, bindOption{})
 		tag = s
 	}
 
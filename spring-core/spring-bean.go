@@ -471,6 +471,9 @@ type beanDefinition interface {
 	getDestroy() *runnable        // 返回 Bean 的销毁函数
 	getFile() string              // 返回 Bean 注册点所在文件的名称
 	getLine() int                 // 返回 Bean 注册点所在文件的行数
+
+	getConfigPrefix() (string, bool) // 返回配置属性 Bean 的属性名前缀
+	isValueBound() bool              // 返回属性值是否已经在注入之前完成了绑定
 }
 
 // BeanDefinition 用于存储 Bean 的各种元数据
@@ -490,6 +493,13 @@ type BeanDefinition struct {
 	destroy *runnable // 销毁函数
 
 	exports map[reflect.Type]struct{} // 严格导出的接口类型
+
+	refreshable bool // 属性值刷新时是否重新绑定 value 标签
+
+	configuration bool   // 是否是配置属性 Bean
+	configPrefix  string // 配置属性 Bean 的属性名前缀
+
+	valueBound bool // 属性值是否已经在注入之前完成了绑定和校验
 }
 
 // newBeanDefinition BeanDefinition 的构造函数
@@ -511,9 +521,10 @@ func newBeanDefinition(name string, bean springBean) *BeanDefinition {
 			}
 		}
 
-		// 排除 spring-boot 包下面的 spring-boot-singlet.go 文件
+		// 排除 spring-boot 包下面的 spring-boot-singlet.go 和 spring-boot-module.go 文件
 		if strings.Contains(file0, "/spring-boot/") {
-			if strings.HasSuffix(file0, "spring-boot-singlet.go") {
+			if strings.HasSuffix(file0, "spring-boot-singlet.go") ||
+				strings.HasSuffix(file0, "spring-boot-module.go") {
 				continue
 			}
 		}
@@ -745,6 +756,35 @@ func (d *BeanDefinition) Primary(primary bool) *BeanDefinition {
 	return d
 }
 
+// Refreshable 设置 Bean 在属性值刷新时重新绑定 value 标签，只支持结构体指针 Bean。
+func (d *BeanDefinition) Refreshable() *BeanDefinition {
+	if t := d.Type(); t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		panic(errors.New("refreshable bean must be pointer to struct"))
+	}
+	d.refreshable = true
+	return d
+}
+
+// IsRefreshable 返回 Bean 在属性值刷新时是否重新绑定 value 标签
+func (d *BeanDefinition) IsRefreshable() bool {
+	return d.refreshable
+}
+
+// ConfigurationPrefix 返回配置属性 Bean 的属性名前缀，不是配置属性 Bean 时返回 false。
+func (d *BeanDefinition) ConfigurationPrefix() (string, bool) {
+	return d.configPrefix, d.configuration
+}
+
+// getConfigPrefix 返回配置属性 Bean 的属性名前缀
+func (d *BeanDefinition) getConfigPrefix() (string, bool) {
+	return d.configPrefix, d.configuration
+}
+
+// isValueBound 返回属性值是否已经在注入之前完成了绑定
+func (d *BeanDefinition) isValueBound() bool {
+	return d.valueBound
+}
+
 // validLifeCycleFunc 判断是否是合法的用于 Bean 生命周期控制的函数，生命周期函数的要求：
 // 至少一个参数，且第一个参数的类型必须是 Bean 的类型，没有返回值或者只能返回 error 类型值。
 func validLifeCycleFunc(fn interface{}, beanType reflect.Type) (reflect.Type, bool) {
@@ -828,6 +868,18 @@ func ToBeanDefinition(name string, i interface{}) *BeanDefinition {
 	return ValueToBeanDefinition(name, reflect.ValueOf(i))
 }
 
+// ConfigurationToBeanDefinition 将配置属性 Bean 转换为 BeanDefinition 对象，Bean 的
+// 名称是 prefix，字段的 value 标签使用相对于 prefix 的属性名，属性值刷新时重新绑定。
+func ConfigurationToBeanDefinition(prefix string, i interface{}) *BeanDefinition {
+	bd := ToBeanDefinition(prefix, i)
+	if t := bd.Type(); t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		panic(errors.New("configuration properties bean must be pointer to struct"))
+	}
+	bd.configuration = true
+	bd.configPrefix = prefix
+	return bd.Refreshable()
+}
+
 // ValueToBeanDefinition 将 Value 转换为 BeanDefinition 对象
 func ValueToBeanDefinition(name string, v reflect.Value) *BeanDefinition {
 	if !v.IsValid() || SpringUtils.IsNil(v) {
@@ -197,19 +197,29 @@ func (c *expressionCondition) Matches(ctx SpringContext) bool {
 	panic(SpringConst.UnimplementedMethod)
 }
 
-// profileCondition 基于运行环境匹配的 Condition 实现
+// profileCondition 基于运行环境匹配的 Condition 实现，profile 可以是运行环境
+// 表达式，如 prod & !eu、dev | test。
 type profileCondition struct {
 	profile string
+	expr    profileExpr
 }
 
-// NewProfileCondition profileCondition 的构造函数
+// NewProfileCondition profileCondition 的构造函数，表达式无效时 panic。
 func NewProfileCondition(profile string) *profileCondition {
-	return &profileCondition{profile}
+	c := &profileCondition{profile: profile}
+	if strings.TrimSpace(profile) != "" {
+		expr, err := parseProfileExpression(profile)
+		if err != nil {
+			panic(err)
+		}
+		c.expr = expr
+	}
+	return c
 }
 
 // Matches 成功返回 true，失败返回 false
 func (c *profileCondition) Matches(ctx SpringContext) bool {
-	return c.profile == "" || strings.EqualFold(c.profile, ctx.GetProfile())
+	return c.expr == nil || c.expr(ctx.GetProfiles())
 }
 
 // ConditionOp conditionNode 的计算方式
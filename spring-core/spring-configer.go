@@ -89,8 +89,8 @@ type Configer struct {
 	after  []string     // 位于哪些配置函数之后
 }
 
-// newConfiger Configer 的构造函数，fn 不能返回 error 以外的其他值
-func newConfiger(name string, fn interface{}, tags []string) *Configer {
+// NewConfiger Configer 的构造函数，fn 不能返回 error 以外的其他值
+func NewConfiger(name string, fn interface{}, tags []string) *Configer {
 
 	fnType := reflect.TypeOf(fn)
 	if fnType.Kind() != reflect.Func {
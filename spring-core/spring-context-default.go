@@ -60,17 +60,19 @@ func (item *beanCacheItem) store(bd *BeanDefinition) {
 
 // defaultSpringContext SpringContext 的默认实现
 type defaultSpringContext struct {
-	// 属性值列表接口
-	Properties
+	// 属性值列表接口，读写加锁
+	*contextProperties
 
 	// 上下文接口
 	wg     sync.WaitGroup
 	ctx    context.Context
 	cancel context.CancelFunc
 
-	profile   string // 运行环境
-	autoWired bool   // 是否开始自动绑定
-	allAccess bool   // 是否允许注入私有字段
+	profiles  []string // 运行环境
+	autoWired bool     // 是否开始自动绑定
+	allAccess bool     // 是否允许注入私有字段
+
+	converters *converterRegistry // 上下文的类型转换器
 
 	beanMap         map[beanKey]*BeanDefinition // Bean 的集合
 	methodBeans     []*BeanDefinition           // 方法 Beans
@@ -80,22 +82,25 @@ type defaultSpringContext struct {
 	configers    *list.List // 配置方法集合
 	destroyers   *list.List // 销毁函数集合
 	destroyerMap map[beanKey]*destroyer
+
+	refreshMutex sync.Mutex // 保证属性值刷新串行执行
 }
 
 // NewDefaultSpringContext defaultSpringContext 的构造函数
 func NewDefaultSpringContext() *defaultSpringContext {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &defaultSpringContext{
-		ctx:             ctx,
-		cancel:          cancel,
-		Properties:      NewDefaultProperties(),
-		methodBeans:     make([]*BeanDefinition, 0),
-		beanMap:         make(map[beanKey]*BeanDefinition),
-		beanCacheByName: make(map[string]*beanCacheItem),
-		beanCacheByType: make(map[reflect.Type]*beanCacheItem),
-		configers:       list.New(),
-		destroyers:      list.New(),
-		destroyerMap:    make(map[beanKey]*destroyer),
+		ctx:               ctx,
+		cancel:            cancel,
+		contextProperties: newContextProperties(),
+		converters:        newConverterRegistry(defaultConverters),
+		methodBeans:       make([]*BeanDefinition, 0),
+		beanMap:           make(map[beanKey]*BeanDefinition),
+		beanCacheByName:   make(map[string]*beanCacheItem),
+		beanCacheByType:   make(map[reflect.Type]*beanCacheItem),
+		configers:         list.New(),
+		destroyers:        list.New(),
+		destroyerMap:      make(map[beanKey]*destroyer),
 	}
 }
 
@@ -104,14 +109,24 @@ func (ctx *defaultSpringContext) Context() context.Context {
 	return ctx.ctx
 }
 
-// GetProfile 返回运行环境
+// GetProfile 返回逗号分隔的运行环境
 func (ctx *defaultSpringContext) GetProfile() string {
-	return ctx.profile
+	return strings.Join(ctx.profiles, ",")
 }
 
-// SetProfile 设置运行环境
+// SetProfile 设置运行环境，多个运行环境使用逗号分隔
 func (ctx *defaultSpringContext) SetProfile(profile string) {
-	ctx.profile = profile
+	ctx.SetProfiles(ParseProfiles(profile)...)
+}
+
+// GetProfiles 返回所有的运行环境
+func (ctx *defaultSpringContext) GetProfiles() []string {
+	return append([]string{}, ctx.profiles...)
+}
+
+// SetProfiles 设置运行环境
+func (ctx *defaultSpringContext) SetProfiles(profiles ...string) {
+	ctx.profiles = append([]string{}, profiles...)
 }
 
 // AllAccess 返回是否允许访问私有字段
@@ -124,6 +139,28 @@ func (ctx *defaultSpringContext) SetAllAccess(allAccess bool) {
 	ctx.allAccess = allAccess
 }
 
+// RegisterTypeConverter 注册上下文的类型转换器，函数原型 func(string)type 或者
+// func(string)(type,error)，优先于全局的类型转换器。
+func (ctx *defaultSpringContext) RegisterTypeConverter(fn interface{}) {
+	ctx.converters.registerType(fn)
+}
+
+// RegisterConverter 注册上下文的具名类型转换器，通过 conv 标签使用，优先于全局
+// 的具名类型转换器，重复注册会 panic。
+func (ctx *defaultSpringContext) RegisterConverter(name string, fn interface{}) {
+	ctx.converters.registerName(name, fn)
+}
+
+// BindProperty 根据类型获取属性值，优先使用上下文的类型转换器。
+func (ctx *defaultSpringContext) BindProperty(key string, i interface{}) {
+	ctx.BindPropertyIf(key, i, false)
+}
+
+// BindPropertyIf 根据类型获取属性值，优先使用上下文的类型转换器。
+func (ctx *defaultSpringContext) BindPropertyIf(key string, i interface{}, allAccess bool) {
+	ctx.read(func(p Properties) { bindProperty(p, key, i, allAccess, ctx.converters) })
+}
+
 // checkAutoWired 检查是否已调用 AutoWireBeans 方法
 func (ctx *defaultSpringContext) checkAutoWired() {
 	if !ctx.autoWired {
@@ -229,6 +266,26 @@ func (ctx *defaultSpringContext) RegisterNameMethodBeanFn(name string, method in
 	return ctx.RegisterNameMethodBean(name, parent, methodName, tags...)
 }
 
+// RegisterBeanDefinition 注册 BeanDefinition 对象，重复注册会 panic。
+func (ctx *defaultSpringContext) RegisterBeanDefinition(bd *BeanDefinition) {
+	if _, ok := bd.bean.(*fakeMethodBean); ok { // 成员方法 Bean 需要延迟注册
+		ctx.checkRegistration()
+		ctx.methodBeans = append(ctx.methodBeans, bd)
+		return
+	}
+	ctx.registerBeanDefinition(bd)
+}
+
+// RegisterConfigurationProperties 注册配置属性 Bean，bean 必须是结构体指针，字段
+// 的 value 标签使用相对于 prefix 的属性名。Bean 的名称是 prefix，只绑定一次，可以
+// 像其他 Bean 一样按类型注入，属性值刷新时重新绑定。构造函数的同类型结构体参数也
+// 使用它的值，而不是重新绑定。重复注册会 panic。
+func (ctx *defaultSpringContext) RegisterConfigurationProperties(prefix string, bean interface{}) *BeanDefinition {
+	bd := ConfigurationToBeanDefinition(prefix, bean)
+	ctx.registerBeanDefinition(bd)
+	return bd
+}
+
 // GetBean 获取单例 Bean，若多于 1 个则 panic；找到返回 true 否则返回 false。
 // 它和 FindBean 的区别是它在调用后能够保证返回的 Bean 已经完成了注入和绑定过程。
 func (ctx *defaultSpringContext) GetBean(i interface{}, selector ...BeanSelector) bool {
@@ -613,6 +670,9 @@ func (ctx *defaultSpringContext) AutoWireBeans() {
 	}()
 
 	ctx.runConfigers(assembly)
+
+	// Config 函数可以设置属性值，因此在执行 Config 函数之后绑定和校验属性值
+	ctx.validateBeans()
 	ctx.wireBeans(assembly)
 
 	ctx.sortDestroyers()
@@ -689,11 +749,16 @@ func (ctx *defaultSpringContext) Config(fn interface{}, tags ...string) *Confige
 
 // ConfigWithName 注册一个配置函数，名称的作用是对 Config 进行排重和排顺序。
 func (ctx *defaultSpringContext) ConfigWithName(name string, fn interface{}, tags ...string) *Configer {
-	configer := newConfiger(name, fn, tags)
-	ctx.configers.PushBack(configer)
+	configer := NewConfiger(name, fn, tags)
+	ctx.RegisterConfiger(configer)
 	return configer
 }
 
+// RegisterConfiger 注册一个已经创建好的配置函数
+func (ctx *defaultSpringContext) RegisterConfiger(configer *Configer) {
+	ctx.configers.PushBack(configer)
+}
+
 // SafeGoroutine 安全地启动一个 goroutine
 func (ctx *defaultSpringContext) SafeGoroutine(fn GoFunc) {
 	ctx.wg.Add(1)
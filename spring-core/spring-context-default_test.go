@@ -3138,3 +3138,113 @@ func TestDefaultSpringContext_Destroy(t *testing.T) {
 
 	assert.Equal(t, destroyArray, []int{1, 2, 2, 4})
 }
+
+type RefreshableConfig struct {
+	Port   int    `value:"${server.port}"`
+	Host   string `value:"${server.host:=localhost}"`
+	Nested struct {
+		Timeout int `value:"${server.timeout:=1}"`
+	}
+	Counter int
+}
+
+func TestDefaultSpringContext_RefreshProperties(t *testing.T) {
+
+	ctx := SpringCore.NewDefaultSpringContext()
+	ctx.SetProperty("server.port", 8080)
+
+	c := new(RefreshableConfig)
+	fixed := new(RefreshableConfig)
+	ctx.RegisterNameBean("c", c).Refreshable()
+	ctx.RegisterNameBean("fixed", fixed)
+	ctx.AutoWireBeans()
+
+	c.Counter = 3
+
+	p := SpringCore.NewDefaultProperties()
+	p.SetProperty("server.port", 9090)
+	p.SetProperty("server.timeout", 5)
+
+	changed, err := ctx.RefreshProperties(p)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, changed, []string{"server.port", "server.timeout"})
+	assert.Equal(t, c.Port, 9090)
+	assert.Equal(t, c.Host, "localhost")
+	assert.Equal(t, c.Nested.Timeout, 5)
+	assert.Equal(t, c.Counter, 3)
+	assert.Equal(t, fixed.Port, 8080)
+	assert.Equal(t, ctx.GetProperty("server.port"), 9090)
+
+	// 属性值没有变化
+	changed, err = ctx.RefreshProperties(p)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(changed), 0)
+
+	// 绑定失败时属性值和 Bean 保持不变
+	bad := SpringCore.NewDefaultProperties()
+	bad.SetProperty("server.port", "abc")
+	bad.SetProperty("server.timeout", 6)
+
+	_, err = ctx.RefreshProperties(bad)
+	assert.Matches(t, err.Error(), "property value server.port isn't int type")
+	assert.Equal(t, c.Port, 9090)
+	assert.Equal(t, c.Nested.Timeout, 5)
+	assert.Equal(t, ctx.GetProperty("server.timeout"), 5)
+
+	assert.Panic(t, func() {
+		SpringCore.ToBeanDefinition("", []int{1}).Refreshable()
+	}, "refreshable bean must be pointer to struct")
+}
+
+func TestDefaultSpringContext_RefreshPropertiesAPI(t *testing.T) {
+
+	ctx := SpringCore.NewDefaultSpringContext()
+
+	p := SpringCore.NewDefaultProperties()
+	p.SetProperty("server.port", 8080)
+	p.SetProperty("server.host", "localhost")
+	_, err := ctx.RefreshProperties(p)
+	assert.Equal(t, err, nil)
+
+	// 第一次刷新之后通过代码设置的属性值在之后的刷新中保留
+	ctx.SetProperty("server.host", "example.com")
+	ctx.SetProperty("app.name", "demo")
+
+	p.SetProperty("server.port", 9090)
+	changed, err := ctx.RefreshProperties(p)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, changed, []string{"server.port"})
+	assert.Equal(t, ctx.GetProperty("server.port"), 9090)
+	assert.Equal(t, ctx.GetProperty("server.host"), "example.com")
+	assert.Equal(t, ctx.GetProperty("app.name"), "demo")
+}
+
+func TestDefaultSpringContext_RefreshPropertiesConcurrently(t *testing.T) {
+
+	ctx := SpringCore.NewDefaultSpringContext()
+	ctx.SetProperty("server.port", 0)
+
+	c := new(RefreshableConfig)
+	ctx.RegisterBean(c).Refreshable()
+	ctx.AutoWireBeans()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			ctx.GetIntProperty("server.port")
+			ctx.GetProperties()
+		}
+	}()
+
+	for i := 1; i <= 100; i++ {
+		p := SpringCore.NewDefaultProperties()
+		p.SetProperty("server.port", i)
+		_, err := ctx.RefreshProperties(p)
+		assert.Equal(t, err, nil)
+	}
+
+	<-done
+	assert.Equal(t, ctx.GetIntProperty("server.port"), int64(100))
+	assert.Equal(t, c.Port, 100)
+}
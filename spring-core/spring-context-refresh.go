@@ -0,0 +1,153 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringCore
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/go-spring/go-spring-parent/spring-utils"
+)
+
+// fieldUpdate 属性值刷新时需要更新的字段
+type fieldUpdate struct {
+	field reflect.Value // Bean 的字段
+	value reflect.Value // 字段的新值
+}
+
+// RefreshProperties 使用新的属性值替换上下文的属性值，然后重新绑定可刷新 Bean
+// 的 value 标签，返回发生变化的属性名。任何一个 Bean 绑定失败都会返回错误，此时
+// 上下文的属性值和所有 Bean 的字段值都保持不变。第一次刷新之后通过 SetProperty 等
+// 方法设置的属性值，比如 Config 函数设置的属性值，会覆盖 p 中的同名属性值，因此应用
+// 可以使用第一次刷新设置从属性源加载的属性值。
+//
+// 属性值的替换和 Bean 字段的更新在上下文属性值的写锁内完成，对于通过上下文读取属性
+// 值的 goroutine 是原子的；但是直接读取 Bean 字段的 goroutine 和字段的更新之间没有
+// 同步，需要并发读取的字段应该自行加锁，或者通过 PropertyChangeListener 等方式获取
+// 新的值。
+func (ctx *defaultSpringContext) RefreshProperties(p Properties) ([]string, error) {
+	ctx.refreshMutex.Lock()
+	defer ctx.refreshMutex.Unlock()
+
+	// 拷贝一份新的属性值，保留属性值的来源，然后覆盖通过代码设置的属性值
+	np := ctx.newProperties()
+	copyProperties(p, np)
+	ctx.merge(np)
+
+	changed := diffProperties(ctx.GetProperties(), np.GetProperties())
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	// 先在 Bean 的副本上绑定新的属性值，全部成功之后再更新 Bean 的字段
+	updates, err := ctx.bindRefreshableBeans(np)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.swap(np, func() {
+		for _, u := range updates {
+			u.field.Set(u.value)
+		}
+	})
+	return changed, nil
+}
+
+// bindRefreshableBeans 在可刷新 Bean 的副本上绑定新的属性值，返回需要更新的字段。
+func (ctx *defaultSpringContext) bindRefreshableBeans(p Properties) (updates []fieldUpdate, err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	val := &validation{}
+	for _, bd := range ctx.beanMap {
+		if !bd.refreshable || bd.status != beanStatus_Wired {
+			continue
+		}
+
+		ev := bd.Value().Elem()
+		et := ev.Type()
+
+		var etName string // 可能是内置类型
+		if etName = et.Name(); etName == "" {
+			etName = et.String()
+		}
+
+		cv := reflect.New(et).Elem()
+		cv.Set(ev)
+
+		// 配置属性 Bean 的字段使用相对于前缀的属性名
+		prefix := bd.configPrefix
+
+		opt := bindOption{
+			propNamePrefix: prefix,
+			fullPropName:   prefix,
+			allAccess:      ctx.AllAccess(),
+			fieldName:      etName,
+			converters:     ctx.converters,
+			validation:     val,
+		}
+
+		bindStruct(p, cv, opt)
+		validateStruct(p, cv, prefix, opt)
+
+		updates = collectValueFields(ev, cv, ctx.AllAccess(), updates)
+	}
+	return updates, val.err()
+}
+
+// collectValueFields 收集结构体中带有 value 标签的字段及其在副本中的值，遍历规则和
+// bindStruct 一致，这样刷新时只更新 value 标签绑定的字段。
+func collectValueFields(v reflect.Value, cv reflect.Value, allAccess bool, updates []fieldUpdate) []fieldUpdate {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		ft := t.Field(i)
+		fv := SpringUtils.ValuePatchIf(v.Field(i), allAccess)
+		cfv := SpringUtils.ValuePatchIf(cv.Field(i), allAccess)
+
+		if _, ok := ft.Tag.Lookup("value"); ok {
+			updates = append(updates, fieldUpdate{field: fv, value: cfv})
+			continue
+		}
+
+		if ft.Type.Kind() == reflect.Struct {
+			updates = collectValueFields(fv, cfv, allAccess, updates)
+		}
+	}
+	return updates
+}
+
+// diffProperties 返回新旧属性值中发生变化的属性名，包括新增和删除的属性。
+func diffProperties(old map[string]interface{}, new map[string]interface{}) []string {
+	var changed []string
+	for k, v := range new {
+		if ov, ok := old[k]; !ok || !reflect.DeepEqual(ov, v) {
+			changed = append(changed, k)
+		}
+	}
+	for k := range old {
+		if _, ok := new[k]; !ok {
+			changed = append(changed, k)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
@@ -37,18 +37,32 @@ type SpringContext interface {
 	// Context 返回上下文接口
 	Context() context.Context
 
-	// GetProfile 返回运行环境
+	// GetProfile 返回逗号分隔的运行环境
 	GetProfile() string
 
-	// SetProfile 设置运行环境
+	// SetProfile 设置运行环境，多个运行环境使用逗号分隔
 	SetProfile(profile string)
 
+	// GetProfiles 返回所有的运行环境
+	GetProfiles() []string
+
+	// SetProfiles 设置运行环境
+	SetProfiles(profiles ...string)
+
 	// AllAccess 返回是否允许访问私有字段
 	AllAccess() bool
 
 	// SetAllAccess 设置是否允许访问私有字段
 	SetAllAccess(allAccess bool)
 
+	// RegisterTypeConverter 注册上下文的类型转换器，函数原型 func(string)type 或者
+	// func(string)(type,error)，优先于全局的类型转换器。
+	RegisterTypeConverter(fn interface{})
+
+	// RegisterConverter 注册上下文的具名类型转换器，通过 conv 标签使用，优先于全局
+	// 的具名类型转换器，重复注册会 panic。
+	RegisterConverter(name string, fn interface{})
+
 	// RegisterBean 注册单例 Bean，不指定名称，重复注册会 panic。
 	RegisterBean(bean interface{}) *BeanDefinition
 
@@ -79,6 +93,15 @@ type SpringContext interface {
 	// method 形如 ServerInterface.Consumer (接口) 或 (*Server).Consumer (类型)。
 	RegisterNameMethodBeanFn(name string, method interface{}, tags ...string) *BeanDefinition
 
+	// RegisterBeanDefinition 注册 BeanDefinition 对象，重复注册会 panic。
+	RegisterBeanDefinition(bd *BeanDefinition)
+
+	// RegisterConfigurationProperties 注册配置属性 Bean，bean 必须是结构体指针，字段
+	// 的 value 标签使用相对于 prefix 的属性名。Bean 的名称是 prefix，只绑定一次，可以
+	// 像其他 Bean 一样按类型注入，属性值刷新时重新绑定。构造函数的同类型结构体参数也
+	// 使用它的值，而不是重新绑定。重复注册会 panic。
+	RegisterConfigurationProperties(prefix string, bean interface{}) *BeanDefinition
+
 	// AutoWireBeans 对所有 Bean 进行依赖注入和属性绑定
 	AutoWireBeans()
 
@@ -121,6 +144,14 @@ type SpringContext interface {
 	// ConfigWithName 注册一个配置函数，名称的作用是对 Config 进行排重和排顺序。
 	ConfigWithName(name string, fn interface{}, tags ...string) *Configer
 
+	// RegisterConfiger 注册一个已经创建好的配置函数
+	RegisterConfiger(configer *Configer)
+
+	// RefreshProperties 使用新的属性值替换上下文的属性值，然后重新绑定可刷新 Bean
+	// 的 value 标签，返回发生变化的属性名。任何一个 Bean 绑定失败都会返回错误，此时
+	// 上下文的属性值和所有 Bean 的字段值都保持不变。
+	RefreshProperties(p Properties) ([]string, error)
+
 	// SafeGoroutine 安全地启动一个 goroutine
 	SafeGoroutine(fn GoFunc)
 }
@@ -0,0 +1,191 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringCore
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 运行环境表达式：由运行环境名称和 !、&、| 以及括号组成，例如 prod & !eu、
+// dev | test、(dev | test) & mysql，运算符的优先级从高到低依次为 !、&、|。
+// 运行环境名称的比较不区分大小写。
+
+// profileExpr 解析后的运行环境表达式
+type profileExpr func(profiles []string) bool
+
+// profileParser 运行环境表达式的递归下降解析器
+type profileParser struct {
+	expr   string
+	tokens []string
+	pos    int
+}
+
+// ParseProfiles 解析逗号分隔的运行环境列表，忽略空白项。
+func ParseProfiles(s string) []string {
+	var profiles []string
+	for _, profile := range strings.Split(s, ",") {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles
+}
+
+// MatchProfiles 判断运行环境表达式是否匹配 profiles 中的运行环境，表达式无效时 panic。
+func MatchProfiles(expression string, profiles []string) bool {
+	expr, err := parseProfileExpression(expression)
+	if err != nil {
+		panic(err)
+	}
+	return expr(profiles)
+}
+
+// parseProfileExpression 解析运行环境表达式
+func parseProfileExpression(expression string) (profileExpr, error) {
+	p := &profileParser{expr: expression, tokens: tokenizeProfileExpression(expression)}
+	if len(p.tokens) == 0 {
+		return nil, p.error("empty expression")
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.tokens) {
+		return nil, p.error("unexpected \"" + p.tokens[p.pos] + "\"")
+	}
+	return expr, nil
+}
+
+// tokenizeProfileExpression 将运行环境表达式拆分为运算符、括号和运行环境名称
+func tokenizeProfileExpression(expression string) []string {
+	var tokens []string
+	start := -1
+
+	for i, r := range expression {
+		switch r {
+		case '!', '&', '|', '(', ')', ' ', '\t':
+			if start >= 0 {
+				tokens = append(tokens, expression[start:i])
+				start = -1
+			}
+			if r != ' ' && r != '\t' {
+				tokens = append(tokens, string(r))
+			}
+		default:
+			if start < 0 {
+				start = i
+			}
+		}
+	}
+
+	if start >= 0 {
+		tokens = append(tokens, expression[start:])
+	}
+	return tokens
+}
+
+func (p *profileParser) error(msg string) error {
+	return fmt.Errorf("invalid profile expression \"%s\": %s", p.expr, msg)
+}
+
+// next 返回下一个 token，没有更多的 token 时返回空字符串
+func (p *profileParser) next() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+// parseOr 解析 a | b
+func (p *profileParser) parseOr() (profileExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.next() == "|" {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(profiles []string) bool { return l(profiles) || right(profiles) }
+	}
+	return left, nil
+}
+
+// parseAnd 解析 a & b
+func (p *profileParser) parseAnd() (profileExpr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.next() == "&" {
+		p.pos++
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(profiles []string) bool { return l(profiles) && right(profiles) }
+	}
+	return left, nil
+}
+
+// parseNot 解析 !a、(a) 以及运行环境名称
+func (p *profileParser) parseNot() (profileExpr, error) {
+
+	token := p.next()
+	p.pos++
+
+	switch token {
+	case "":
+		return nil, p.error("unexpected end")
+	case "!":
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return func(profiles []string) bool { return !expr(profiles) }, nil
+	case "(":
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, p.error("missing \")\"")
+		}
+		p.pos++
+		return expr, nil
+	case "&", "|", ")":
+		return nil, p.error("unexpected \"" + token + "\"")
+	}
+
+	return func(profiles []string) bool {
+		for _, profile := range profiles {
+			if strings.EqualFold(profile, token) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringCore_test
+
+import (
+	"testing"
+
+	"github.com/go-spring/go-spring/spring-core"
+	"github.com/magiconair/properties/assert"
+)
+
+func TestMatchProfiles(t *testing.T) {
+
+	profiles := []string{"prod", "Metrics"}
+
+	testData := []struct {
+		expr   string
+		expect bool
+	}{
+		{"prod", true},
+		{"PROD", true},
+		{"metrics", true},
+		{"dev", false},
+		{"!dev", true},
+		{"!!prod", true},
+		{"prod & !eu", true},
+		{"prod & eu", false},
+		{"dev | test", false},
+		{"dev | prod", true},
+		{"dev | prod & metrics", true},
+		{"(dev | prod) & !metrics", false},
+		{"!(dev|test)&prod", true},
+	}
+
+	for _, d := range testData {
+		assert.Equal(t, SpringCore.MatchProfiles(d.expr, profiles), d.expect, d.expr)
+	}
+
+	assert.Equal(t, SpringCore.MatchProfiles("!prod", nil), true)
+
+	for _, expr := range []string{"", "prod &", "& prod", "(prod", "prod)", "prod eu", "!"} {
+		assert.Panic(t, func() { SpringCore.MatchProfiles(expr, profiles) }, "invalid profile expression")
+	}
+}
+
+func TestDefaultSpringContext_Profiles(t *testing.T) {
+
+	ctx := SpringCore.NewDefaultSpringContext()
+	assert.Equal(t, ctx.GetProfile(), "")
+	assert.Equal(t, len(ctx.GetProfiles()), 0)
+
+	ctx.SetProfile("prod, metrics,")
+	assert.Equal(t, ctx.GetProfiles(), []string{"prod", "metrics"})
+	assert.Equal(t, ctx.GetProfile(), "prod,metrics")
+
+	ctx.SetProfiles("dev")
+	assert.Equal(t, ctx.GetProfile(), "dev")
+
+	ctx.SetProfiles("prod", "metrics")
+	ctx.RegisterNameBean("eu", &BeanZero{1}).ConditionOnProfile("prod & eu")
+	ctx.RegisterNameBean("metrics", &BeanZero{2}).ConditionOnProfile("prod & !eu")
+	ctx.RegisterNameBean("dev", &BeanZero{3}).ConditionOnProfile("dev | test")
+	ctx.AutoWireBeans()
+
+	var b *BeanZero
+	assert.Equal(t, ctx.GetBean(&b), true)
+	assert.Equal(t, b.Int, 2)
+
+	assert.Panic(t, func() {
+		SpringCore.ConditionOnProfile("prod &")
+	}, "invalid profile expression \"prod &\": unexpected end")
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringCore
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-spring/go-spring-parent/spring-utils"
+)
+
+// ConfigurationProperty 配置属性 Bean 的字段绑定的属性值
+type ConfigurationProperty struct {
+	Name  string      `json:"name"`  // 属性名
+	Value interface{} `json:"value"` // 字段的值，敏感的属性值被替换为 ******
+	Field string      `json:"field"` // 声明属性的字段名
+}
+
+// CollectConfigurationProperties 返回配置属性 Bean 的字段绑定的属性值，用于诊断信息
+// 的输出，按照属性名排序，不是配置属性 Bean 时返回 nil。结构体类型的字段展开为它的字段。
+func CollectConfigurationProperties(p Properties, bd *BeanDefinition) []ConfigurationProperty {
+
+	prefix, ok := bd.ConfigurationPrefix()
+	if !ok {
+		return nil
+	}
+
+	c := newSensitiveChecker(p)
+	result := collectConfigurationFields(c, bd.Value().Elem(), prefix, nil)
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// collectConfigurationFields 收集结构体字段绑定的属性值，遍历规则和 bindStruct 一致，
+// 属性值是否敏感按照绑定时的规则判断，即属性名宽松匹配，并且跟随引用和默认值。
+func collectConfigurationFields(c *sensitiveChecker, v reflect.Value, prefix string, result []ConfigurationProperty) []ConfigurationProperty {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		ft := t.Field(i)
+		fv := SpringUtils.ValuePatch(v.Field(i))
+
+		tag, ok := ft.Tag.Lookup("value")
+		if !ok {
+			if ft.Type.Kind() == reflect.Struct {
+				result = collectConfigurationFields(c, fv, prefix, result)
+			}
+			continue
+		}
+
+		if !(strings.HasPrefix(tag, "${") && strings.HasSuffix(tag, "}")) {
+			continue
+		}
+
+		ss := strings.SplitN(tag[2:len(tag)-1], ":=", 2)
+		key := ss[0]
+		if key == "" {
+			key = prefix
+		} else {
+			key = joinPropertyKey(prefix, key)
+		}
+
+		if fv.Kind() == reflect.Struct && isCompositeType(fv.Type(), nil) {
+			result = collectConfigurationFields(c, fv, key, result)
+			continue
+		}
+
+		value := fv.Interface()
+		if isSensitiveField(c, strings.ToLower(key), ss) {
+			value = MaskedPropertyValue
+		}
+
+		result = append(result, ConfigurationProperty{
+			Name:  key,
+			Value: value,
+			Field: t.String() + "." + ft.Name,
+		})
+	}
+	return result
+}
+
+// isSensitiveField 返回字段绑定的属性值是否敏感，属性不存在时判断 value 标签中的默认值。
+func isSensitiveField(c *sensitiveChecker, key string, tag []string) bool {
+	if _, _, ok := c.lookup(key); ok {
+		return c.isSensitive(key, nil)
+	}
+	return len(tag) > 1 && c.isSensitiveValue(tag[1], nil)
+}
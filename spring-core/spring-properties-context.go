@@ -0,0 +1,349 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringCore
+
+import (
+	"io"
+	"net/url"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// contextProperties 上下文的属性值，读写属性值时加锁，这样刷新属性值时替换属性值
+// 列表对于并发的读取是原子的。第一次刷新属性值之后，它还记录通过代码设置的属性值，
+// 比如 Config 函数设置的属性值，之后刷新属性值时保留它们。刷新前后的属性值共享计算
+// 属性的缓存，因此 ${random.int} 等计算属性的值在上下文的生命周期内保持不变。
+type contextProperties struct {
+	mutex    sync.RWMutex
+	curr     Properties         // 当前生效的属性值
+	api      *defaultProperties // 通过代码设置的属性值，第一次刷新之前为 nil
+	computed *computedCache     // 计算属性的缓存
+}
+
+// newContextProperties contextProperties 的构造函数
+func newContextProperties() *contextProperties {
+	p := &contextProperties{computed: new(computedCache)}
+	p.curr = p.newProperties()
+	return p
+}
+
+// newProperties 返回一个空的属性值列表，和上下文共享计算属性的缓存。
+func (p *contextProperties) newProperties() *defaultProperties {
+	np := NewDefaultProperties()
+	np.computed = p.computed
+	return np
+}
+
+// computedCache 返回缓存计算属性的对象
+func (p *contextProperties) computedCache() *computedCache {
+	return p.computed
+}
+
+// copyProperties 将 from 中的属性值及其来源拷贝到 to，保留属性名的大小写。
+func copyProperties(from Properties, to Properties) {
+	for k, v := range from.GetProperties() {
+		name := from.GetPropertyName(k)
+		if origin, ok := from.GetPropertyOrigin(k); ok {
+			to.SetPropertyWithOrigin(name, v, origin)
+		} else {
+			to.SetProperty(name, v)
+		}
+	}
+}
+
+// mergeAPI 将通过代码设置的属性值覆盖到 to，调用者需要持有锁。属性值相同时保留 to
+// 中的属性值来源。
+func (p *contextProperties) mergeAPI(to Properties) {
+	if p.api == nil {
+		return
+	}
+	raw := to.GetProperties()
+	for k, v := range p.api.GetProperties() {
+		if ov, ok := raw[k]; ok && reflect.DeepEqual(ov, v) {
+			continue
+		}
+		name := p.api.GetPropertyName(k)
+		if origin, ok := p.api.GetPropertyOrigin(k); ok {
+			to.SetPropertyWithOrigin(name, v, origin)
+		} else {
+			to.SetProperty(name, v)
+		}
+	}
+}
+
+// merge 在读锁的保护下将通过代码设置的属性值覆盖到 to
+func (p *contextProperties) merge(to Properties) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	p.mergeAPI(to)
+}
+
+// read 在读锁的保护下使用当前的属性值
+func (p *contextProperties) read(fn func(curr Properties)) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	fn(p.curr)
+}
+
+// swap 在写锁的保护下替换当前的属性值，然后执行 fn，通过代码设置的属性值被保留，
+// 并且开始记录通过代码设置的属性值。
+func (p *contextProperties) swap(np Properties, fn func()) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.mergeAPI(np)
+	p.curr = np
+	if p.api == nil {
+		p.api = NewDefaultProperties()
+	}
+	fn()
+}
+
+// LoadProperties 加载属性配置文件，属性值和通过 SetProperty 设置的属性值一样处理。
+func (p *contextProperties) LoadProperties(filename string) {
+	np := NewDefaultProperties()
+	np.LoadProperties(filename)
+	p.setProperties(np)
+}
+
+// ReadProperties 读取属性配置文件，属性值和通过 SetProperty 设置的属性值一样处理。
+func (p *contextProperties) ReadProperties(reader io.Reader, configType string) {
+	np := NewDefaultProperties()
+	np.ReadProperties(reader, configType)
+	p.setProperties(np)
+}
+
+// setProperties 设置 np 中的所有属性值
+func (p *contextProperties) setProperties(np Properties) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.api != nil {
+		copyProperties(np, p.api)
+	}
+	copyProperties(np, p.curr)
+}
+
+// GetProperty 返回 keys 中第一个存在的属性值，属性名称统一转成小写。
+func (p *contextProperties) GetProperty(keys ...string) interface{} {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.curr.GetProperty(keys...)
+}
+
+// GetBoolProperty 返回 keys 中第一个存在的布尔型属性值，属性名称统一转成小写。
+func (p *contextProperties) GetBoolProperty(keys ...string) bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.curr.GetBoolProperty(keys...)
+}
+
+// GetIntProperty 返回 keys 中第一个存在的有符号整型属性值，属性名称统一转成小写。
+func (p *contextProperties) GetIntProperty(keys ...string) int64 {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.curr.GetIntProperty(keys...)
+}
+
+// GetUintProperty 返回 keys 中第一个存在的无符号整型属性值，属性名称统一转成小写。
+func (p *contextProperties) GetUintProperty(keys ...string) uint64 {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.curr.GetUintProperty(keys...)
+}
+
+// GetFloatProperty 返回 keys 中第一个存在的浮点型属性值，属性名称统一转成小写。
+func (p *contextProperties) GetFloatProperty(keys ...string) float64 {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.curr.GetFloatProperty(keys...)
+}
+
+// GetStringProperty 返回 keys 中第一个存在的字符串型属性值，属性名称统一转成小写。
+func (p *contextProperties) GetStringProperty(keys ...string) string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.curr.GetStringProperty(keys...)
+}
+
+// GetDurationProperty 返回 keys 中第一个存在的 Duration 类型属性值，属性名称统一转成小写。
+func (p *contextProperties) GetDurationProperty(keys ...string) time.Duration {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.curr.GetDurationProperty(keys...)
+}
+
+// GetTimeProperty 返回 keys 中第一个存在的 Time 类型的属性值，属性名称统一转成小写。
+func (p *contextProperties) GetTimeProperty(keys ...string) time.Time {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.curr.GetTimeProperty(keys...)
+}
+
+// GetBoolPropertyE 返回布尔型属性值。
+func (p *contextProperties) GetBoolPropertyE(key string) (bool, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.curr.GetBoolPropertyE(key)
+}
+
+// GetIntPropertyE 返回有符号整型属性值。
+func (p *contextProperties) GetIntPropertyE(key string) (int64, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.curr.GetIntPropertyE(key)
+}
+
+// GetUintPropertyE 返回无符号整型属性值。
+func (p *contextProperties) GetUintPropertyE(key string) (uint64, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.curr.GetUintPropertyE(key)
+}
+
+// GetFloatPropertyE 返回浮点型属性值。
+func (p *contextProperties) GetFloatPropertyE(key string) (float64, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.curr.GetFloatPropertyE(key)
+}
+
+// GetStringPropertyE 返回字符串型属性值。
+func (p *contextProperties) GetStringPropertyE(key string) (string, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.curr.GetStringPropertyE(key)
+}
+
+// GetDurationPropertyE 返回 Duration 类型属性值。
+func (p *contextProperties) GetDurationPropertyE(key string) (time.Duration, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.curr.GetDurationPropertyE(key)
+}
+
+// GetTimePropertyE 返回 Time 类型的属性值。
+func (p *contextProperties) GetTimePropertyE(key string) (time.Time, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.curr.GetTimePropertyE(key)
+}
+
+// GetStringSlicePropertyE 返回字符串列表类型的属性值，字符串按照逗号切割。
+func (p *contextProperties) GetStringSlicePropertyE(key string) ([]string, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.curr.GetStringSlicePropertyE(key)
+}
+
+// GetStringMapPropertyE 返回 map[string]string 类型的属性值，由以 key 为前缀的属性值组成。
+func (p *contextProperties) GetStringMapPropertyE(key string) (map[string]string, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.curr.GetStringMapPropertyE(key)
+}
+
+// GetByteSizePropertyE 返回字节数，如 64KB、1.5MB、2GiB，使用 1024 进制。
+func (p *contextProperties) GetByteSizePropertyE(key string) (int64, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.curr.GetByteSizePropertyE(key)
+}
+
+// GetURLPropertyE 返回 URL 类型的属性值，URL 必须包含 scheme。
+func (p *contextProperties) GetURLPropertyE(key string) (*url.URL, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.curr.GetURLPropertyE(key)
+}
+
+// GetDefaultProperty 返回属性值，如果没有找到则使用指定的默认值，属性名称统一转成小写。
+func (p *contextProperties) GetDefaultProperty(key string, def interface{}) (interface{}, bool) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.curr.GetDefaultProperty(key, def)
+}
+
+// SetProperty 设置属性值，属性名称统一转成小写，第一次刷新属性值之后设置的属性值
+// 在之后的刷新中保留。
+func (p *contextProperties) SetProperty(key string, value interface{}) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.api != nil {
+		p.api.SetProperty(key, value)
+	}
+	p.curr.SetProperty(key, value)
+}
+
+// SetPropertyWithOrigin 设置属性值并记录属性值的来源，属性名称统一转成小写，第一次
+// 刷新属性值之后设置的属性值在之后的刷新中保留。
+func (p *contextProperties) SetPropertyWithOrigin(key string, value interface{}, origin PropertyOrigin) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.api != nil {
+		p.api.SetPropertyWithOrigin(key, value, origin)
+	}
+	p.curr.SetPropertyWithOrigin(key, value, origin)
+}
+
+// GetPropertyOrigin 返回属性值的来源，属性名称统一转成小写。
+func (p *contextProperties) GetPropertyOrigin(key string) (PropertyOrigin, bool) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.curr.GetPropertyOrigin(key)
+}
+
+// GetPrefixProperties 返回指定前缀的属性值集合，属性名称统一转成小写。
+func (p *contextProperties) GetPrefixProperties(prefix string) map[string]interface{} {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.curr.GetPrefixProperties(prefix)
+}
+
+// GetProperties 返回所有未经解析的属性值的副本，属性名称统一转成小写。
+func (p *contextProperties) GetProperties() map[string]interface{} {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	result := make(map[string]interface{})
+	for k, v := range p.curr.GetProperties() {
+		result[k] = v
+	}
+	return result
+}
+
+// GetPropertyName 返回设置属性值时使用的属性名，保留原始的大小写。
+func (p *contextProperties) GetPropertyName(key string) string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.curr.GetPropertyName(key)
+}
+
+// GetUnusedProperties 返回从未被读取过的属性名，按照属性名排序。
+func (p *contextProperties) GetUnusedProperties() []string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.curr.GetUnusedProperties()
+}
+
+// BindProperty 根据类型获取属性值，属性名称统一转成小写。
+func (p *contextProperties) BindProperty(key string, i interface{}) {
+	p.BindPropertyIf(key, i, false)
+}
+
+// BindPropertyIf 根据类型获取属性值，属性名称统一转成小写。
+func (p *contextProperties) BindPropertyIf(key string, i interface{}, allAccess bool) {
+	p.read(func(curr Properties) { curr.BindPropertyIf(key, i, allAccess) })
+}
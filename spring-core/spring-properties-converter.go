@@ -0,0 +1,241 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringCore
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// 类型转换器将字符串形式的属性值转换为指定的类型，函数原型是 func(string)type 或者
+// func(string)(type,error)，前者只能通过 panic 报告错误。按类型注册的转换器用于该
+// 类型的所有字段，按名称注册的转换器只用于通过 conv 标签指定了名称的字段，如：
+//   Timeout time.Duration `value:"${timeout}" conv:"seconds"`
+// 字段是列表、map 或者指针并且转换器不能直接转换为字段的类型时，转换器用于它们的元素。
+// 转换器可以注册到全局，也可以注册到上下文，上下文优先使用自己的转换器。
+//
+// 内置的类型转换器：time.Duration、time.Time、*url.URL、net.IP、*regexp.Regexp、
+// time.Location 和 *time.Location；内置的具名转换器：bytesize (如 10MB，见
+// parseByteSize)、seconds 和 milliseconds (数字形式的时长)。
+
+func init() {
+
+	// time units are "ns", "us" (or "µs"), "ms", "s", "m", "h"。
+	RegisterTypeConverter(func(s string) (time.Duration, error) {
+		return cast.ToDurationE(s)
+	})
+
+	// 支持非常多的日期格式，参见 cast.StringToDate。
+	RegisterTypeConverter(func(s string) (time.Time, error) {
+		return cast.ToTimeE(s)
+	})
+
+	RegisterTypeConverter(parseURL)
+
+	RegisterTypeConverter(func(s string) (net.IP, error) {
+		if ip := net.ParseIP(strings.TrimSpace(s)); ip != nil {
+			return ip, nil
+		}
+		return nil, fmt.Errorf("invalid ip \"%s\"", s)
+	})
+
+	RegisterTypeConverter(regexp.Compile)
+
+	RegisterTypeConverter(func(s string) (*time.Location, error) {
+		return time.LoadLocation(strings.TrimSpace(s))
+	})
+
+	RegisterTypeConverter(func(s string) (time.Location, error) {
+		loc, err := time.LoadLocation(strings.TrimSpace(s))
+		if err != nil {
+			return time.Location{}, err
+		}
+		return *loc, nil
+	})
+
+	RegisterConverter("bytesize", parseByteSize)
+
+	RegisterConverter("seconds", func(s string) (time.Duration, error) {
+		return parseNumberDuration(s, time.Second)
+	})
+
+	RegisterConverter("milliseconds", func(s string) (time.Duration, error) {
+		return parseNumberDuration(s, time.Millisecond)
+	})
+}
+
+// parseNumberDuration 解析数字形式的时长，数字可以是小数，unit 是数字的单位。
+func parseNumberDuration(s string, unit time.Duration) (time.Duration, error) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number \"%s\"", s)
+	}
+	return time.Duration(f * float64(unit)), nil
+}
+
+// converter 类型转换器
+type converter struct {
+	fn      reflect.Value
+	outType reflect.Type
+}
+
+// newConverter converter 的构造函数，fn 不是合法的类型转换器时返回 false。
+func newConverter(fn interface{}) (*converter, bool) {
+	if t := reflect.TypeOf(fn); validTypeConverter(t) {
+		return &converter{fn: reflect.ValueOf(fn), outType: t.Out(0)}, true
+	}
+	return nil, false
+}
+
+// validTypeConverter 返回是否是合法的类型转换器，类型转换器要求：必须是函数，且
+// 只能有一个字符串类型的输入参数，输出参数是值类型、指针或者列表，可以额外返回一个
+// error 类型的输出参数。
+func validTypeConverter(t reflect.Type) bool {
+
+	// 必须是函数 && 只能有一个输入参数 && 输入参数必须是字符串类型
+	if t == nil || t.Kind() != reflect.Func || t.NumIn() != 1 || t.In(0).Kind() != reflect.String {
+		return false
+	}
+
+	// 只能有一个输出参数，或者额外返回一个 error
+	if t.NumOut() != 1 && !(t.NumOut() == 2 && t.Out(1) == errorType) {
+		return false
+	}
+
+	switch k := t.Out(0).Kind(); k {
+	case reflect.Ptr, reflect.Slice:
+		return true
+	default:
+		return IsValueType(k)
+	}
+}
+
+// canConvertTo 返回转换结果能否赋值给 t 类型，数值类型之间可以相互转换。
+func (c *converter) canConvertTo(t reflect.Type) bool {
+	return c.outType.AssignableTo(t) || (isNumberKind(c.outType.Kind()) && isNumberKind(t.Kind()))
+}
+
+// convert 将字符串转换为 t 类型，转换器 panic 时不做处理。
+func (c *converter) convert(s string, t reflect.Type) (reflect.Value, error) {
+	in := reflect.ValueOf(s).Convert(c.fn.Type().In(0))
+	out := c.fn.Call([]reflect.Value{in})
+	if len(out) > 1 && !out[1].IsNil() {
+		return reflect.Value{}, out[1].Interface().(error)
+	}
+	if r := out[0]; r.Type().AssignableTo(t) {
+		return r, nil
+	} else {
+		return r.Convert(t), nil
+	}
+}
+
+// isNumberKind 返回是否是整数或者浮点数类型
+func isNumberKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// converterRegistry 类型转换器集合，找不到时查找上一级集合。
+type converterRegistry struct {
+	parent *converterRegistry
+	types  map[reflect.Type]*converter // 按类型注册的转换器
+	names  map[string]*converter       // 按名称注册的转换器
+}
+
+// defaultConverters 全局的类型转换器集合
+var defaultConverters = newConverterRegistry(nil)
+
+// newConverterRegistry converterRegistry 的构造函数
+func newConverterRegistry(parent *converterRegistry) *converterRegistry {
+	return &converterRegistry{
+		parent: parent,
+		types:  make(map[reflect.Type]*converter),
+		names:  make(map[string]*converter),
+	}
+}
+
+// registerType 按类型注册转换器，同类型的转换器会被替换。
+func (r *converterRegistry) registerType(fn interface{}) {
+	if c, ok := newConverter(fn); ok {
+		r.types[c.outType] = c
+	} else {
+		panic(errors.New("fn must be func(string)type or func(string)(type,error)"))
+	}
+}
+
+// registerName 按名称注册转换器，重复注册会 panic。
+func (r *converterRegistry) registerName(name string, fn interface{}) {
+	if _, ok := r.names[name]; ok {
+		panic(fmt.Errorf("converter \"%s\" already registered", name))
+	}
+	if c, ok := newConverter(fn); ok {
+		r.names[name] = c
+	} else {
+		panic(errors.New("fn must be func(string)type or func(string)(type,error)"))
+	}
+}
+
+// findType 返回 t 类型的转换器，r 为 nil 时使用全局的转换器。
+func (r *converterRegistry) findType(t reflect.Type) (*converter, bool) {
+	if r == nil {
+		r = defaultConverters
+	}
+	for ; r != nil; r = r.parent {
+		if c, ok := r.types[t]; ok {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// findName 返回指定名称的转换器，r 为 nil 时使用全局的转换器。
+func (r *converterRegistry) findName(name string) (*converter, bool) {
+	if r == nil {
+		r = defaultConverters
+	}
+	for ; r != nil; r = r.parent {
+		if c, ok := r.names[name]; ok {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// RegisterTypeConverter 注册全局的类型转换器，函数原型 func(string)type 或者
+// func(string)(type,error)，同类型的转换器会被替换。
+func RegisterTypeConverter(fn interface{}) {
+	defaultConverters.registerType(fn)
+}
+
+// RegisterConverter 注册全局的具名类型转换器，通过 conv 标签使用，函数原型同
+// RegisterTypeConverter，重复注册会 panic。
+func RegisterConverter(name string, fn interface{}) {
+	defaultConverters.registerName(name, fn)
+}
@@ -0,0 +1,194 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringCore_test
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-spring/go-spring/spring-core"
+	"github.com/magiconair/properties/assert"
+)
+
+type converterConfig struct {
+	Endpoint *url.URL       `value:"${endpoint}"`
+	Proxy    *url.URL       `value:"${proxy}"`
+	Bind     net.IP         `value:"${bind:=0.0.0.0}"`
+	Pattern  *regexp.Regexp `value:"${pattern}"`
+	Zone     time.Location  `value:"${zone:=UTC}"`
+	ZonePtr  *time.Location `value:"${zone:=UTC}"`
+
+	MaxBody   int64          `value:"${max-body}" conv:"bytesize"`
+	Buffer    int            `value:"${buffer:=4KB}" conv:"bytesize"`
+	Limits    []int64        `value:"${limits:=1KB,2KB}" conv:"bytesize"`
+	Quotas    map[string]int `value:"${quotas}" conv:"bytesize"`
+	Timeout   time.Duration  `value:"${timeout}" conv:"seconds"`
+	Interval  *time.Duration `value:"${interval:=250}" conv:"milliseconds"`
+	KeepAlive *time.Duration `value:"${keep-alive}" conv:"seconds"`
+}
+
+func TestConverters_BuiltIn(t *testing.T) {
+
+	p := SpringCore.NewDefaultProperties()
+	p.SetProperty("endpoint", "https://example.com:8443/api")
+	p.SetProperty("pattern", "^a+b$")
+	p.SetProperty("max-body", "10MB")
+	p.SetProperty("quotas.read", "1MB")
+	p.SetProperty("quotas.write", "512")
+	p.SetProperty("timeout", 1.5)
+
+	var c converterConfig
+	p.BindProperty("", &c)
+
+	assert.Equal(t, c.Endpoint.Host, "example.com:8443")
+	assert.Equal(t, c.Proxy == nil, true)
+	assert.Equal(t, c.Bind.String(), "0.0.0.0")
+	assert.Equal(t, c.Pattern.MatchString("aab"), true)
+	assert.Equal(t, c.Zone.String(), "UTC")
+	assert.Equal(t, c.ZonePtr, time.UTC)
+
+	assert.Equal(t, c.MaxBody, int64(10<<20))
+	assert.Equal(t, c.Buffer, 4<<10)
+	assert.Equal(t, c.Limits, []int64{1 << 10, 2 << 10})
+	assert.Equal(t, c.Quotas, map[string]int{"read": 1 << 20, "write": 512})
+	assert.Equal(t, c.Timeout, 1500*time.Millisecond)
+	assert.Equal(t, *c.Interval, 250*time.Millisecond)
+	assert.Equal(t, c.KeepAlive == nil, true)
+}
+
+func TestConverters_Error(t *testing.T) {
+
+	p := SpringCore.NewDefaultProperties()
+	p.SetPropertyWithOrigin("bind", "localhost", SpringCore.PropertyOrigin{Source: "a.properties", Line: 3})
+	p.SetProperty("size", "10XB")
+
+	t.Run("convert", func(t *testing.T) {
+		var c struct {
+			Bind net.IP `value:"${bind}"`
+		}
+		assert.Panic(t, func() {
+			p.BindProperty("", &c)
+		}, `property value bind isn't net.IP type: invalid ip "localhost" \(from a.properties:3\)`)
+
+		var s struct {
+			Size int64 `value:"${size}" conv:"bytesize"`
+		}
+		assert.Panic(t, func() {
+			p.BindProperty("", &s)
+		}, `property value size isn't int64 type: invalid byte size "10XB"`)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		var c struct {
+			Size int64 `value:"${size}" conv:"unknown"`
+		}
+		assert.Panic(t, func() {
+			p.BindProperty("", &c)
+		}, `converter "unknown" not found`)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		var c struct {
+			Size string `value:"${size}" conv:"bytesize"`
+		}
+		assert.Panic(t, func() {
+			p.BindProperty("", &c)
+		}, `converter "bytesize" can't convert to string`)
+	})
+
+	t.Run("register", func(t *testing.T) {
+		assert.Panic(t, func() {
+			SpringCore.RegisterConverter("bytesize", strconv.Atoi)
+		}, `converter "bytesize" already registered`)
+
+		assert.Panic(t, func() {
+			SpringCore.RegisterConverter("atoi", func(s string) (int, string) { return 0, s })
+		}, `fn must be func\(string\)type or func\(string\)\(type,error\)`)
+	})
+}
+
+type temperature float64
+
+type converterBean struct {
+	Temperature temperature `value:"${temperature}"`
+	Name        string      `value:"${name}" conv:"upper"`
+}
+
+func parseTemperature(s string) (temperature, error) {
+	if !strings.HasSuffix(s, "C") {
+		return 0, fmt.Errorf("unknown unit in %s", s)
+	}
+	f, err := strconv.ParseFloat(strings.TrimSuffix(s, "C"), 64)
+	return temperature(f), err
+}
+
+func TestDefaultSpringContext_Converters(t *testing.T) {
+
+	newContext := func() SpringCore.SpringContext {
+		ctx := SpringCore.NewDefaultSpringContext()
+		ctx.SetProperty("temperature", "36.5C")
+		ctx.SetProperty("name", "spring")
+		return ctx
+	}
+
+	t.Run("context", func(t *testing.T) {
+		ctx := newContext()
+		ctx.RegisterTypeConverter(parseTemperature)
+		ctx.RegisterConverter("upper", strings.ToUpper)
+
+		b := &converterBean{}
+		ctx.RegisterBean(b)
+		ctx.AutoWireBeans()
+
+		assert.Equal(t, b.Temperature, temperature(36.5))
+		assert.Equal(t, b.Name, "SPRING")
+
+		var v struct {
+			Name string `value:"${name}" conv:"upper"`
+		}
+		ctx.BindProperty("", &v)
+		assert.Equal(t, v.Name, "SPRING")
+
+		assert.Panic(t, func() {
+			ctx.RegisterConverter("upper", strings.ToLower)
+		}, `converter "upper" already registered`)
+
+		// 上下文的具名转换器可以覆盖全局的具名转换器
+		ctx.RegisterConverter("bytesize", strconv.Atoi)
+	})
+
+	t.Run("isolated", func(t *testing.T) {
+		ctx := newContext()
+		ctx.RegisterBean(&converterBean{})
+		assert.Panic(t, func() {
+			ctx.AutoWireBeans()
+		}, "property value temperature isn't float type")
+
+		var v struct {
+			Name string `value:"${name}" conv:"upper"`
+		}
+		assert.Panic(t, func() {
+			ctx.BindProperty("", &v)
+		}, `converter "upper" not found`)
+	})
+}
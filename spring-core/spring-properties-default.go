@@ -17,12 +17,15 @@
 package SpringCore
 
 import (
+	"encoding"
 	"errors"
 	"fmt"
 	"io"
+	"net/url"
 	"reflect"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-spring/go-spring-parent/spring-logger"
@@ -31,38 +34,42 @@ import (
 	"github.com/spf13/viper"
 )
 
-func init() {
-
-	// 注册时长转换函数 string -> time.Duration converter
-	// time units are "ns", "us" (or "µs"), "ms", "s", "m", "h"。
-	RegisterTypeConverter(func(s string) time.Duration {
-		r, err := cast.ToDurationE(s)
-		SpringUtils.Panic(err).When(err != nil)
-		return r
-	})
-
-	// 注册日期转换函数 string -> time.Time converter
-	// 支持非常多的日期格式，参见 cast.StringToDate。
-	RegisterTypeConverter(func(s string) time.Time {
-		r, err := cast.ToTimeE(s)
-		SpringUtils.Panic(err).When(err != nil)
-		return r
-	})
-}
+// textUnmarshalerType encoding.TextUnmarshaler 的类型
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
 
 // defaultProperties Properties 的默认实现
 type defaultProperties struct {
 	properties map[string]interface{}
+	uniform    map[string]string         // 宽松形式的属性名 -> 属性名
+	origins    map[string]PropertyOrigin // 属性名 -> 属性值的来源
+	names      map[string]string         // 属性名 -> 设置属性值时使用的原始属性名
+	roots      map[string]bool           // 属性名中下标之前的部分的宽松形式
+	used       sync.Map                  // 被读取过的属性名
+	computed   *computedCache            // 计算属性的缓存
 }
 
 // NewDefaultProperties defaultProperties 的构造函数
 func NewDefaultProperties() *defaultProperties {
 	return &defaultProperties{
 		properties: make(map[string]interface{}),
+		uniform:    make(map[string]string),
+		origins:    make(map[string]PropertyOrigin),
+		names:      make(map[string]string),
+		roots:      make(map[string]bool),
+		computed:   new(computedCache),
+	}
+}
+
+// newMapProperties 使用 map 中的属性值创建 defaultProperties 对象
+func newMapProperties(m map[string]interface{}) *defaultProperties {
+	p := NewDefaultProperties()
+	for k, v := range m {
+		p.SetProperty(k, v)
 	}
+	return p
 }
 
-func (p *defaultProperties) readProperties(reader func(*viper.Viper) error) {
+func (p *defaultProperties) readProperties(source string, reader func(*viper.Viper) error) {
 
 	v := viper.New()
 	err := reader(v)
@@ -73,8 +80,8 @@ func (p *defaultProperties) readProperties(reader func(*viper.Viper) error) {
 
 	for _, key := range keys {
 		val := v.Get(key)
-		p.SetProperty(key, val)
-		SpringLogger.Tracef("%s=%v", key, val)
+		p.SetPropertyWithOrigin(key, val, PropertyOrigin{Source: source})
+		SpringLogger.Tracef("%s=%v", key, MaskPropertyValue(val))
 	}
 }
 
@@ -82,7 +89,7 @@ func (p *defaultProperties) readProperties(reader func(*viper.Viper) error) {
 func (p *defaultProperties) LoadProperties(filename string) {
 	SpringLogger.Debug("load properties from file: ", filename)
 
-	p.readProperties(func(v *viper.Viper) error {
+	p.readProperties(filename, func(v *viper.Viper) error {
 		v.SetConfigFile(filename)
 		return v.ReadInConfig()
 	})
@@ -92,17 +99,87 @@ func (p *defaultProperties) LoadProperties(filename string) {
 func (p *defaultProperties) ReadProperties(reader io.Reader, configType string) {
 	SpringLogger.Debug("load properties from reader type: ", configType)
 
-	p.readProperties(func(v *viper.Viper) error {
+	p.readProperties("", func(v *viper.Viper) error {
 		v.SetConfigType(configType)
 		return v.ReadConfig(reader)
 	})
 }
 
+// findKey 返回和 key 宽松匹配的已存储的属性名
+func (p *defaultProperties) findKey(key string) (string, bool) {
+	key = strings.ToLower(key)
+
+	// 首先进行精确匹配
+	if _, ok := p.properties[key]; ok {
+		return key, true
+	}
+
+	// 然后进行宽松匹配
+	if k, ok := p.uniform[uniformPropertyKey(key)]; ok {
+		return k, true
+	}
+
+	// 最后尝试环境变量形式，如 WEB_SERVER_PORT
+	if !strings.Contains(key, ".") && strings.Contains(key, "_") {
+		if k, ok := p.uniform[uniformPropertyKey(EnvToPropertyKey(key))]; ok {
+			return k, true
+		}
+	}
+
+	return "", false
+}
+
+// getRawProperty 返回未经解析的属性值，属性名称宽松匹配。下标形式的属性值被组装
+// 成列表，也可以访问列表和 map 中的元素，详见 buildIndexedList。
+func (p *defaultProperties) getRawProperty(key string) (interface{}, bool) {
+	if v, ok := p.getLocalProperty(key); ok {
+		return v, true
+	}
+	return p.getNestedProperty(key)
+}
+
+// computedCache 返回缓存计算属性的对象
+func (p *defaultProperties) computedCache() *computedCache {
+	return p.computed
+}
+
+// getLocalProperty 返回属性名对应的属性值或者下标形式的属性值组装的列表
+func (p *defaultProperties) getLocalProperty(key string) (interface{}, bool) {
+
+	k, ok := p.findKey(key)
+	if ok {
+		p.used.Store(k, true)
+	}
+
+	// 列表和下标形式的属性值进行合并
+	if !ok || isList(p.properties[k]) {
+		if list, found := p.getIndexedProperty(key, p.properties[k]); found {
+			return list, true
+		}
+	}
+
+	if ok {
+		return p.properties[k], true
+	}
+	return nil, false
+}
+
+// GetPropertyName 返回设置属性值时使用的属性名，属性名称宽松匹配，找不到时返回 key。
+func (p *defaultProperties) GetPropertyName(key string) string {
+	if k, ok := p.findKey(key); ok {
+		if name, ok := p.names[k]; ok {
+			return name
+		}
+		return k
+	}
+	return key
+}
+
 // GetProperty 返回 keys 中第一个存在的属性值，属性名称统一转成小写。
 func (p *defaultProperties) GetProperty(keys ...string) interface{} {
 	for _, key := range keys {
-		if v, ok := p.properties[strings.ToLower(key)]; ok {
-			return v
+		if v, ok := lookupProperty(p, key); ok {
+			return resolveProperty(p, key, v)
 		}
 	}
 	return nil
@@ -143,15 +220,108 @@ func (p *defaultProperties) GetTimeProperty(keys ...string) time.Time {
 	return cast.ToTime(p.GetProperty(keys...))
 }
 
-// SetProperty 设置属性值，属性名称统一转成小写。
+// GetBoolPropertyE 返回布尔型属性值，属性值不存在时返回 ErrPropertyNotFound 错误。
+func (p *defaultProperties) GetBoolPropertyE(key string) (bool, error) {
+	return getBoolPropertyE(p, key)
+}
+
+// GetIntPropertyE 返回有符号整型属性值，属性值不存在时返回 ErrPropertyNotFound 错误。
+func (p *defaultProperties) GetIntPropertyE(key string) (int64, error) {
+	return getIntPropertyE(p, key)
+}
+
+// GetUintPropertyE 返回无符号整型属性值，属性值不存在时返回 ErrPropertyNotFound 错误。
+func (p *defaultProperties) GetUintPropertyE(key string) (uint64, error) {
+	return getUintPropertyE(p, key)
+}
+
+// GetFloatPropertyE 返回浮点型属性值，属性值不存在时返回 ErrPropertyNotFound 错误。
+func (p *defaultProperties) GetFloatPropertyE(key string) (float64, error) {
+	return getFloatPropertyE(p, key)
+}
+
+// GetStringPropertyE 返回字符串型属性值，属性值不存在时返回 ErrPropertyNotFound 错误。
+func (p *defaultProperties) GetStringPropertyE(key string) (string, error) {
+	return getStringPropertyE(p, key)
+}
+
+// GetDurationPropertyE 返回 Duration 类型的属性值，属性值不存在时返回 ErrPropertyNotFound 错误。
+func (p *defaultProperties) GetDurationPropertyE(key string) (time.Duration, error) {
+	return getDurationPropertyE(p, key)
+}
+
+// GetTimePropertyE 返回 Time 类型的属性值，属性值不存在时返回 ErrPropertyNotFound 错误。
+func (p *defaultProperties) GetTimePropertyE(key string) (time.Time, error) {
+	return getTimePropertyE(p, key)
+}
+
+// GetStringSlicePropertyE 返回字符串列表类型的属性值，字符串按照逗号切割，属性值不存在时返回 ErrPropertyNotFound 错误。
+func (p *defaultProperties) GetStringSlicePropertyE(key string) ([]string, error) {
+	return getStringSlicePropertyE(p, key)
+}
+
+// GetStringMapPropertyE 返回 map[string]string 类型的属性值，由以 key 为前缀的属性值组成，属性值不存在时返回 ErrPropertyNotFound 错误。
+func (p *defaultProperties) GetStringMapPropertyE(key string) (map[string]string, error) {
+	return getStringMapPropertyE(p, key)
+}
+
+// GetByteSizePropertyE 返回字节数，如 64KB、1.5MB、2GiB，使用 1024 进制，属性值不存在时返回 ErrPropertyNotFound 错误。
+func (p *defaultProperties) GetByteSizePropertyE(key string) (int64, error) {
+	return getByteSizePropertyE(p, key)
+}
+
+// GetURLPropertyE 返回 URL 类型的属性值，URL 必须包含 scheme，属性值不存在时返回 ErrPropertyNotFound 错误。
+func (p *defaultProperties) GetURLPropertyE(key string) (*url.URL, error) {
+	return getURLPropertyE(p, key)
+}
+
+// SetProperty 设置属性值，属性名称统一转成小写，同时记录原始的属性名以及清除原有
+// 的来源信息。属性名可以包含下标，如 servers[0].host。
 func (p *defaultProperties) SetProperty(key string, value interface{}) {
-	p.properties[strings.ToLower(key)] = value
+	name := key
+	key = strings.ToLower(key)
+	p.properties[key] = value
+	p.uniform[uniformPropertyKey(key)] = key
+	if name != key {
+		p.names[key] = name
+	} else {
+		delete(p.names, key)
+	}
+	for _, root := range indexedRoots(key) {
+		p.roots[root] = true
+	}
+	delete(p.origins, key)
+}
+
+// SetPropertyWithOrigin 设置属性值并记录属性值的来源，属性名称统一转成小写。
+func (p *defaultProperties) SetPropertyWithOrigin(key string, value interface{}, origin PropertyOrigin) {
+	p.SetProperty(key, value)
+	p.origins[strings.ToLower(key)] = origin
+}
+
+// GetPropertyOrigin 返回属性值的来源，属性名称宽松匹配。下标形式组装的列表使用
+// 第一个下标形式的属性值的来源，列表和 map 中的元素使用列表和 map 的来源。
+func (p *defaultProperties) GetPropertyOrigin(key string) (PropertyOrigin, bool) {
+	if k, ok := p.findKey(key); ok {
+		origin, ok := p.origins[k]
+		return origin, ok
+	}
+	if keys := p.indexedKeys(key); len(keys) > 0 {
+		origin, ok := p.origins[keys[0]]
+		return origin, ok
+	}
+	if parent, _, ok := parentKey(key); ok {
+		if v, ok := p.getRawProperty(parent); ok && isContainer(v) {
+			return p.GetPropertyOrigin(parent)
+		}
+	}
+	return PropertyOrigin{}, false
 }
 
 // GetDefaultProperty 返回属性值，如果没有找到则使用指定的默认值，属性名称统一转成小写。
 func (p *defaultProperties) GetDefaultProperty(key string, def interface{}) (interface{}, bool) {
-	if v, ok := p.properties[strings.ToLower(key)]; ok {
-		return v, true
+	if v, ok := lookupProperty(p, key); ok {
+		return resolveProperty(p, key, v), true
 	}
 	return def, false
 }
@@ -161,24 +331,43 @@ func (p *defaultProperties) GetPrefixProperties(prefix string) map[string]interf
 	prefix = strings.ToLower(prefix)
 	result := make(map[string]interface{})
 	for k, v := range p.properties {
-		if k == prefix || strings.HasPrefix(k, prefix+".") {
-			result[k] = v
+		if key, ok := relaxedPrefixMatch(k, prefix); ok {
+			result[key] = resolveProperty(p, k, v)
+			p.used.Store(k, true)
 		}
 	}
 	return result
 }
 
-// GetProperties 返回所有的属性值，属性名称统一转成小写。
+// GetProperties 返回所有未经解析的属性值，属性名称统一转成小写。
 func (p *defaultProperties) GetProperties() map[string]interface{} {
 	return p.properties
 }
 
+// GetUnusedProperties 返回从未被读取过的属性名，按照属性名排序。
+func (p *defaultProperties) GetUnusedProperties() []string {
+	var result []string
+	for k := range p.properties {
+		if _, ok := p.used.Load(k); !ok {
+			result = append(result, k)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
 // bindOption 属性值绑定可选项
 type bindOption struct {
 	propNamePrefix string // 属性名前缀
 	fullPropName   string // 完整属性名
 	fieldName      string // 结构体字段的名称
 	allAccess      bool   // 私有字段是否绑定
+	preserveCase   bool   // map 的键是否保留原始的大小写
+
+	converters *converterRegistry // 类型转换器，nil 表示使用全局的类型转换器
+	conv       string             // conv 标签指定的类型转换器的名称
+	validate   string             // validate 标签指定的校验规则
+	validation *validation        // 收集校验错误，nil 表示校验失败时立即 panic
 }
 
 // bindStruct 对结构体进行属性值绑定
@@ -198,15 +387,20 @@ func bindStruct(p Properties, v reflect.Value, opt bindOption) {
 			fullPropName:   opt.fullPropName,
 			fieldName:      subFieldName,
 			allAccess:      opt.allAccess,
+			converters:     opt.converters,
+			validation:     opt.validation,
 		}
 
 		if tag, ok := ft.Tag.Lookup("value"); ok {
+			subOpt.preserveCase = ft.Tag.Get("case") == "preserve"
+			subOpt.conv = ft.Tag.Get("conv")
+			subOpt.validate = ft.Tag.Get("validate")
 			bindStructField(p, fv, tag, subOpt)
 			continue
 		}
 
-		// 匿名嵌套需要处理，不是结构体的具名字段无需处理
-		if ft.Anonymous || ft.Type.Kind() == reflect.Struct {
+		// 结构体字段包括匿名嵌套的结构体需要处理，嵌套的指针等其他类型的字段无需处理
+		if ft.Type.Kind() == reflect.Struct {
 			bindStruct(p, fv, subOpt)
 		}
 	}
@@ -220,12 +414,8 @@ func bindStructField(p Properties, v reflect.Value, str string, opt bindOption)
 		panic(fmt.Errorf("%s 属性绑定的语法发生错误", opt.fieldName))
 	}
 
-	// 指针不能作为属性绑定的目标
-	if v.Kind() == reflect.Ptr {
-		panic(fmt.Errorf("%s 属性绑定的目标不能是指针", opt.fieldName))
-	}
-
-	ss := strings.Split(str[2:len(str)-1], ":=")
+	// 只按照第一个 := 进行切割，默认值中可以包含嵌套的引用
+	ss := strings.SplitN(str[2:len(str)-1], ":=", 2)
 
 	var (
 		key string
@@ -243,14 +433,34 @@ func bindStructField(p Properties, v reflect.Value, str string, opt bindOption)
 
 	// 属性名如果有前缀要加上前缀
 	if opt.propNamePrefix != "" {
-		key = opt.propNamePrefix + "." + key
+		if key == "" {
+			key = opt.propNamePrefix
+		} else {
+			key = opt.propNamePrefix + "." + key
+		}
 	}
 
 	if len(ss) > 1 {
 		def = ss[1]
 	}
 
+	// 收集绑定错误时字段绑定失败不影响其他字段的绑定
+	if opt.validation != nil && opt.validation.bindErrors {
+		defer func() {
+			if r := recover(); r != nil {
+				if key == "" {
+					key = opt.fieldName
+				}
+				opt.validation.addBindError(key, r)
+			}
+		}()
+	}
+
 	bindValue(p, v, key, def, opt)
+
+	if opt.validate != "" {
+		validateValue(p, v, key, opt)
+	}
 }
 
 func getPropertyValue(p Properties, k reflect.Kind, key string, def interface{}, opt bindOption) interface{} {
@@ -260,16 +470,20 @@ func getPropertyValue(p Properties, k reflect.Kind, key string, def interface{},
 		return val
 	}
 
-	// Map 和 Struct 类型获取具有相同前缀的属性值
-	if k == reflect.Map || k == reflect.Struct {
+	// Map、Struct 和 interface{} 类型获取具有相同前缀的属性值
+	if k == reflect.Map || k == reflect.Struct || k == reflect.Interface {
 		if prefixValue := p.GetPrefixProperties(key); len(prefixValue) > 0 {
 			return prefixValue
 		}
 	}
 
-	// 最后使用默认值
+	// 最后使用默认值，默认值中可以包含引用
 	if def != nil {
-		return def
+		if v, err := resolveValue(p, def, nil); err == nil {
+			return v
+		} else {
+			panic(fmt.Errorf("%s %v", opt.fieldName, err))
+		}
 	}
 
 	panic(fmt.Errorf("%s properties \"%s\" not config", opt.fieldName, opt.fullPropName))
@@ -281,12 +495,45 @@ func bindValue(p Properties, v reflect.Value, key string, def interface{}, opt b
 	t := v.Type()
 	k := t.Kind()
 
-	// 存在值类型转换器的情况下结构体优先使用属性值绑定
-	if fn, ok := typeConverters[t]; ok {
-		propValue := getPropertyValue(p, k, key, def, opt)
-		fnValue := reflect.ValueOf(fn)
-		out := fnValue.Call([]reflect.Value{reflect.ValueOf(propValue)})
-		v.Set(out[0])
+	// 使用 conv 标签指定的类型转换器，不能直接转换时用于列表、map 和指针的元素
+	if opt.conv != "" {
+		c, ok := opt.converters.findName(opt.conv)
+		if !ok {
+			panic(fmt.Errorf("%s converter \"%s\" not found", opt.fieldName, opt.conv))
+		}
+		if c.canConvertTo(t) {
+			bindConverter(p, v, key, def, c, opt)
+			return
+		}
+		if k != reflect.Slice && k != reflect.Map && k != reflect.Ptr {
+			panic(fmt.Errorf("%s converter \"%s\" can't convert to %s", opt.fieldName, opt.conv, t.String()))
+		}
+	} else if c, ok := opt.converters.findType(t); ok {
+		// 存在值类型转换器的情况下结构体优先使用属性值绑定
+		bindConverter(p, v, key, def, c, opt)
+		return
+	}
+
+	// 指针只在属性值存在时分配内存，否则设置为 nil，用于可选的配置
+	if k == reflect.Ptr {
+		if def == nil && !hasProperty(p, key) {
+			v.Set(reflect.Zero(t))
+			return
+		}
+		ev := reflect.New(t.Elem())
+		bindValue(p, ev.Elem(), key, def, opt)
+		v.Set(ev)
+		return
+	}
+
+	// 实现了 encoding.TextUnmarshaler 接口的类型使用字符串形式的属性值
+	if v.CanAddr() && reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		propValue := getPropertyValue(p, reflect.String, key, def, opt)
+		if s, err := cast.ToStringE(propValue); err != nil {
+			panic(fmt.Errorf("property value %s isn't string type%s", opt.fullPropName, originSuffix(p, key)))
+		} else if err = v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			panic(fmt.Errorf("property value %s isn't %s type: %v%s", opt.fullPropName, t.String(), err, originSuffix(p, key)))
+		}
 		return
 	}
 
@@ -297,7 +544,10 @@ func bindValue(p Properties, v reflect.Value, key string, def interface{}, opt b
 				fullPropName:   opt.fullPropName,
 				fieldName:      opt.fieldName,
 				allAccess:      opt.allAccess,
+				converters:     opt.converters,
+				validation:     opt.validation,
 			})
+			validateStruct(p, v, key, opt)
 			return
 		} else { // 前面已经校验过是否存在值类型转换器
 			panic(fmt.Errorf("%s 结构体字段不能指定默认值", opt.fieldName))
@@ -311,31 +561,31 @@ func bindValue(p Properties, v reflect.Value, key string, def interface{}, opt b
 		if u, err := cast.ToUint64E(propValue); err == nil {
 			v.SetUint(u)
 		} else {
-			panic(fmt.Errorf("property value %s isn't uint type", opt.fullPropName))
+			panic(fmt.Errorf("property value %s isn't uint type%s", opt.fullPropName, originSuffix(p, key)))
 		}
 	case reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8, reflect.Int:
 		if i, err := cast.ToInt64E(propValue); err == nil {
 			v.SetInt(i)
 		} else {
-			panic(fmt.Errorf("property value %s isn't int type", opt.fullPropName))
+			panic(fmt.Errorf("property value %s isn't int type%s", opt.fullPropName, originSuffix(p, key)))
 		}
 	case reflect.Float64, reflect.Float32:
 		if f, err := cast.ToFloat64E(propValue); err == nil {
 			v.SetFloat(f)
 		} else {
-			panic(fmt.Errorf("property value %s isn't float type", opt.fullPropName))
+			panic(fmt.Errorf("property value %s isn't float type%s", opt.fullPropName, originSuffix(p, key)))
 		}
 	case reflect.String:
 		if s, err := cast.ToStringE(propValue); err == nil {
 			v.SetString(s)
 		} else {
-			panic(fmt.Errorf("property value %s isn't string type", opt.fullPropName))
+			panic(fmt.Errorf("property value %s isn't string type%s", opt.fullPropName, originSuffix(p, key)))
 		}
 	case reflect.Bool:
 		if b, err := cast.ToBoolE(propValue); err == nil {
 			v.SetBool(b)
 		} else {
-			panic(fmt.Errorf("property value %s isn't bool type", opt.fullPropName))
+			panic(fmt.Errorf("property value %s isn't bool type%s", opt.fullPropName, originSuffix(p, key)))
 		}
 	case reflect.Slice:
 		elemType := v.Type().Elem()
@@ -346,20 +596,11 @@ func bindValue(p Properties, v reflect.Value, key string, def interface{}, opt b
 			propValue = strings.Split(s, ",")
 		}
 
-		// 处理使用类型转换器的场景
-		if fn, ok := typeConverters[elemType]; ok {
-			if s0, err := cast.ToStringSliceE(propValue); err == nil {
-				sv := reflect.MakeSlice(t, len(s0), len(s0))
-				fnValue := reflect.ValueOf(fn)
-				for i, iv := range s0 {
-					res := fnValue.Call([]reflect.Value{reflect.ValueOf(iv)})
-					sv.Index(i).Set(res[0])
-				}
-				v.Set(sv)
-				return
-			} else {
-				panic(fmt.Errorf("property value %s isn't []string type", opt.fullPropName))
-			}
+		// 使用类型转换器或者实现了 encoding.TextUnmarshaler 接口的元素逐个进行绑定
+		_, hasConverter := opt.converters.findType(elemType)
+		if hasConverter || opt.conv != "" || reflect.PtrTo(elemType).Implements(textUnmarshalerType) {
+			bindSliceElements(p, v, key, propValue, opt)
+			return
 		}
 
 		switch elemKind {
@@ -367,185 +608,281 @@ func bindValue(p Properties, v reflect.Value, key string, def interface{}, opt b
 			if i, err := SpringUtils.ToUint64SliceE(propValue); err == nil {
 				v.Set(reflect.ValueOf(i))
 			} else {
-				panic(fmt.Errorf("property value %s isn't []uint64 type", opt.fullPropName))
+				panic(fmt.Errorf("property value %s isn't []uint64 type%s", opt.fullPropName, originSuffix(p, key)))
 			}
 		case reflect.Uint32:
 			if i, err := SpringUtils.ToUint32SliceE(propValue); err == nil {
 				v.Set(reflect.ValueOf(i))
 			} else {
-				panic(fmt.Errorf("property value %s isn't []uint32 type", opt.fullPropName))
+				panic(fmt.Errorf("property value %s isn't []uint32 type%s", opt.fullPropName, originSuffix(p, key)))
 			}
 		case reflect.Uint16:
 			if i, err := SpringUtils.ToUint16SliceE(propValue); err == nil {
 				v.Set(reflect.ValueOf(i))
 			} else {
-				panic(fmt.Errorf("property value %s isn't []uint16 type", opt.fullPropName))
+				panic(fmt.Errorf("property value %s isn't []uint16 type%s", opt.fullPropName, originSuffix(p, key)))
 			}
 		case reflect.Uint8:
 			if i, err := SpringUtils.ToUint8SliceE(propValue); err == nil {
 				v.Set(reflect.ValueOf(i))
 			} else {
-				panic(fmt.Errorf("property value %s isn't []uint8 type", opt.fullPropName))
+				panic(fmt.Errorf("property value %s isn't []uint8 type%s", opt.fullPropName, originSuffix(p, key)))
 			}
 		case reflect.Uint:
 			if i, err := SpringUtils.ToUintSliceE(propValue); err == nil {
 				v.Set(reflect.ValueOf(i))
 			} else {
-				panic(fmt.Errorf("property value %s isn't []uint type", opt.fullPropName))
+				panic(fmt.Errorf("property value %s isn't []uint type%s", opt.fullPropName, originSuffix(p, key)))
 			}
 		case reflect.Int64:
 			if i, err := SpringUtils.ToInt64SliceE(propValue); err == nil {
 				v.Set(reflect.ValueOf(i))
 			} else {
-				panic(fmt.Errorf("property value %s isn't []int64 type", opt.fullPropName))
+				panic(fmt.Errorf("property value %s isn't []int64 type%s", opt.fullPropName, originSuffix(p, key)))
 			}
 		case reflect.Int32:
 			if i, err := SpringUtils.ToInt32SliceE(propValue); err == nil {
 				v.Set(reflect.ValueOf(i))
 			} else {
-				panic(fmt.Errorf("property value %s isn't []int32 type", opt.fullPropName))
+				panic(fmt.Errorf("property value %s isn't []int32 type%s", opt.fullPropName, originSuffix(p, key)))
 			}
 		case reflect.Int16:
 			if i, err := SpringUtils.ToInt16SliceE(propValue); err == nil {
 				v.Set(reflect.ValueOf(i))
 			} else {
-				panic(fmt.Errorf("property value %s isn't []int16 type", opt.fullPropName))
+				panic(fmt.Errorf("property value %s isn't []int16 type%s", opt.fullPropName, originSuffix(p, key)))
 			}
 		case reflect.Int8:
 			if i, err := SpringUtils.ToInt8SliceE(propValue); err == nil {
 				v.Set(reflect.ValueOf(i))
 			} else {
-				panic(fmt.Errorf("property value %s isn't []int8 type", opt.fullPropName))
+				panic(fmt.Errorf("property value %s isn't []int8 type%s", opt.fullPropName, originSuffix(p, key)))
 			}
 		case reflect.Int:
 			if i, err := SpringUtils.ToIntSliceE(propValue); err == nil {
 				v.Set(reflect.ValueOf(i))
 			} else {
-				panic(fmt.Errorf("property value %s isn't []int type", opt.fullPropName))
+				panic(fmt.Errorf("property value %s isn't []int type%s", opt.fullPropName, originSuffix(p, key)))
 			}
 		case reflect.Float64, reflect.Float32:
-			panic(errors.New("暂未支持"))
+			list := toList(propValue)
+			if list == nil {
+				panic(fmt.Errorf("property value %s isn't []%s type%s", opt.fullPropName, elemKind, originSuffix(p, key)))
+			}
+			sv := reflect.MakeSlice(t, len(list), len(list))
+			for i, e := range list {
+				if s, ok := e.(string); ok {
+					e = strings.TrimSpace(s)
+				}
+				if f, err := cast.ToFloat64E(e); err == nil {
+					sv.Index(i).SetFloat(f)
+				} else {
+					panic(fmt.Errorf("property value %s isn't []%s type%s", opt.fullPropName, elemKind, originSuffix(p, key)))
+				}
+			}
+			v.Set(sv)
 		case reflect.String:
 			if i, err := cast.ToStringSliceE(propValue); err == nil {
 				v.Set(reflect.ValueOf(i))
 			} else {
-				panic(fmt.Errorf("property value %s isn't []string type", opt.fullPropName))
+				panic(fmt.Errorf("property value %s isn't []string type%s", opt.fullPropName, originSuffix(p, key)))
 			}
 		case reflect.Bool:
 			if b, err := cast.ToBoolSliceE(propValue); err == nil {
 				v.Set(reflect.ValueOf(b))
 			} else {
-				panic(fmt.Errorf("property value %s isn't []bool type", opt.fullPropName))
+				panic(fmt.Errorf("property value %s isn't []bool type%s", opt.fullPropName, originSuffix(p, key)))
 			}
 		default:
-			// 处理结构体字段的场景
-			if s, ok := propValue.([]interface{}); ok {
-				result := reflect.MakeSlice(t, len(s), len(s))
-				for i, si := range s {
-					if sv, err := cast.ToStringMapE(si); err == nil {
-						ev := reflect.New(elemType)
-						subFullPropName := fmt.Sprintf("%s[%d]", key, i)
-						bindStruct(&defaultProperties{sv}, ev.Elem(), bindOption{
-							fullPropName: subFullPropName,
-							fieldName:    opt.fieldName,
-							allAccess:    opt.allAccess,
-						})
-						result.Index(i).Set(ev.Elem())
-					} else {
-						panic(fmt.Errorf("property value %s isn't []map[string]interface{}", opt.fullPropName))
-					}
-				}
-				v.Set(result)
-			} else {
-				panic(fmt.Errorf("property value %s isn't []map[string]interface{}", opt.fullPropName))
-			}
+			// 处理结构体、指针、列表以及 map 等类型的元素
+			bindSliceElements(p, v, key, propValue, opt)
 		}
 	case reflect.Map:
-		if t.Key().Kind() != reflect.String {
-			panic(fmt.Errorf("field: %s isn't map[string]interface{}", opt.fieldName))
+		bindMap(p, v, key, propValue, opt)
+	case reflect.Interface:
+		if t.NumMethod() > 0 {
+			panic(errors.New(opt.fieldName + " unsupported type " + t.String()))
+		}
+		if propValue != nil {
+			v.Set(reflect.ValueOf(propValue))
 		}
+	default:
+		panic(errors.New(opt.fieldName + " unsupported type " + v.Kind().String()))
+	}
+}
 
-		elemType := t.Elem()
-		elemKind := elemType.Kind()
+// bindConverter 使用类型转换器进行属性绑定，指针类型的属性值不存在时设置为 nil。
+func bindConverter(p Properties, v reflect.Value, key string, def interface{}, c *converter, opt bindOption) {
+	t := v.Type()
 
-		// 首先处理使用类型转换器的场景
-		if fn, ok := typeConverters[elemType]; ok {
-			if mapValue, err := cast.ToStringMapStringE(propValue); err == nil {
-				prefix := key + "."
-				fnValue := reflect.ValueOf(fn)
-				result := reflect.MakeMap(t)
-				for k0, v0 := range mapValue {
-					res := fnValue.Call([]reflect.Value{reflect.ValueOf(v0)})
-					k0 = strings.TrimPrefix(k0, prefix)
-					result.SetMapIndex(reflect.ValueOf(k0), res[0])
-				}
-				v.Set(result)
-				return
-			} else {
-				panic(fmt.Errorf("property value %s isn't map[string]string", opt.fullPropName))
-			}
+	if t.Kind() == reflect.Ptr && def == nil && !hasProperty(p, key) {
+		v.Set(reflect.Zero(t))
+		return
+	}
+
+	propValue := getPropertyValue(p, t.Kind(), key, def, opt)
+	s, err := cast.ToStringE(propValue)
+	if err != nil {
+		panic(fmt.Errorf("property value %s isn't string type%s", opt.fullPropName, originSuffix(p, key)))
+	}
+
+	r, err := c.convert(s, t)
+	if err != nil {
+		panic(fmt.Errorf("property value %s isn't %s type: %v%s", opt.fullPropName, t.String(), err, originSuffix(p, key)))
+	}
+	v.Set(r)
+}
+
+// bindSliceElements 逐个绑定列表的元素，元素可以通过 key[i] 形式的属性名访问。
+func bindSliceElements(p Properties, v reflect.Value, key string, propValue interface{}, opt bindOption) {
+	t := v.Type()
+
+	list := toList(propValue)
+	if list == nil {
+		panic(fmt.Errorf("property value %s isn't []map[string]interface{}%s", opt.fullPropName, originSuffix(p, key)))
+	}
+
+	// 空字符串表示空列表，比如使用 ${routes:=} 形式的默认值
+	if len(list) == 1 && list[0] == "" {
+		list = list[:0]
+	}
+
+	// 属性值不是列表时，比如使用默认值或者逗号分隔的字符串，元素无法通过
+	// 属性名访问，此时使用临时的属性值集合
+	src := p
+	if raw, ok := lookupProperty(p, key); !ok || !isList(raw) {
+		sub := NewDefaultProperties()
+		sub.SetProperty(key, list)
+		src = inheritOrigins(sub, p, func(string) string { return key })
+	}
+
+	result := reflect.MakeSlice(t, len(list), len(list))
+	for i := range list {
+		bindValue(src, result.Index(i), fmt.Sprintf("%s[%d]", key, i), nil, bindOption{
+			fullPropName: fmt.Sprintf("%s[%d]", opt.fullPropName, i),
+			fieldName:    opt.fieldName,
+			allAccess:    opt.allAccess,
+			preserveCase: opt.preserveCase,
+			converters:   opt.converters,
+			conv:         opt.conv,
+			validation:   opt.validation,
+		})
+	}
+	v.Set(result)
+}
+
+// bindMap 对 map 进行属性绑定，map 的键是去掉前缀之后的属性名，元素是结构体、列表等
+// 复合类型时 map 的键只取去掉前缀之后的第一段属性名，如 m.a.host 对应的键是 a。
+func bindMap(p Properties, v reflect.Value, key string, propValue interface{}, opt bindOption) {
+
+	t := v.Type()
+	if t.Key().Kind() != reflect.String {
+		panic(fmt.Errorf("field: %s isn't map[string]interface{}", opt.fieldName))
+	}
+
+	mapValue, err := cast.ToStringMapE(propValue)
+	if err != nil {
+		panic(fmt.Errorf("property value %s isn't map[string]interface{}%s", opt.fullPropName, originSuffix(p, key)))
+	}
+
+	composite := opt.conv == "" && isCompositeType(t.Elem(), opt.converters)
+
+	// 属性值本身是 map 时使用临时的属性值集合，否则使用前缀形式的属性值
+	src, prefix := p, key
+	var names []string
+	if _, ok := lookupProperty(p, key); ok {
+		src = inheritOrigins(newMapProperties(mapValue), p, func(k string) string {
+			return joinPropertyKey(key, k)
+		})
+		prefix = ""
+		names = flattenMapKeys("", mapValue, !composite)
+	} else {
+		for k := range mapValue {
+			names = append(names, mapKey(p, key, k, opt))
 		}
+	}
 
-		switch elemKind {
-		case reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8, reflect.Uint:
-			panic(errors.New("暂未支持"))
-		case reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8, reflect.Int:
-			panic(errors.New("暂未支持"))
-		case reflect.Float64, reflect.Float32:
-			panic(errors.New("暂未支持"))
-		case reflect.Bool:
-			panic(errors.New("暂未支持"))
-		case reflect.String:
-			if mapValue, err := cast.ToStringMapStringE(propValue); err == nil {
-				prefix := key + "."
-				result := make(map[string]string)
-				for k0, v0 := range mapValue {
-					k0 = strings.TrimPrefix(k0, prefix)
-					result[k0] = v0
-				}
-				v.Set(reflect.ValueOf(result))
-			} else {
-				panic(fmt.Errorf("property value %s isn't map[string]string", opt.fullPropName))
+	result := reflect.MakeMapWithSize(t, len(names))
+	for _, name := range names {
+		if composite {
+			if i := strings.IndexAny(name, ".["); i > 0 {
+				name = name[:i]
 			}
-		default:
-			// 处理结构体字段的场景
-			if mapValue, err := cast.ToStringMapE(propValue); err == nil {
-				temp := make(map[string]map[string]interface{})
-				trimKey := key + "."
-				var ok bool
-
-				// 将一维 map 变成二维 map
-				for k0, v0 := range mapValue {
-					k0 = strings.TrimPrefix(k0, trimKey)
-					sk := strings.Split(k0, ".")
-					var item map[string]interface{}
-					if item, ok = temp[sk[0]]; !ok {
-						item = make(map[string]interface{})
-						temp[sk[0]] = item
-					}
-					item[sk[1]] = v0
-				}
+		}
+		mk := reflect.ValueOf(name).Convert(t.Key())
+		if result.MapIndex(mk).IsValid() {
+			continue
+		}
+		ev := reflect.New(t.Elem()).Elem()
+		bindValue(src, ev, joinPropertyKey(prefix, name), nil, bindOption{
+			fullPropName: joinPropertyKey(opt.fullPropName, name),
+			fieldName:    opt.fieldName,
+			allAccess:    opt.allAccess,
+			preserveCase: opt.preserveCase,
+			converters:   opt.converters,
+			conv:         opt.conv,
+			validation:   opt.validation,
+		})
+		result.SetMapIndex(mk, ev)
+	}
+	v.Set(result)
+}
 
-				result := reflect.MakeMapWithSize(t, len(temp))
-				for k1, v1 := range temp {
-					ev := reflect.New(elemType)
-					subFullPropName := fmt.Sprintf("%s.%s", key, k1)
-					bindStruct(&defaultProperties{v1}, ev.Elem(), bindOption{
-						fullPropName: subFullPropName,
-						fieldName:    opt.fieldName,
-						allAccess:    opt.allAccess,
-					})
-					result.SetMapIndex(reflect.ValueOf(k1), ev.Elem())
-				}
+// isCompositeType 返回绑定时是否需要展开为多个属性值的类型，如结构体、列表和 map。
+func isCompositeType(t reflect.Type, converters *converterRegistry) bool {
+	if _, ok := converters.findType(t); ok {
+		return false
+	}
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Map:
+		return true
+	case reflect.Ptr:
+		return isCompositeType(t.Elem(), converters)
+	}
+	return false
+}
 
-				v.Set(result)
-			} else {
-				panic(fmt.Errorf("property value %s isn't map[string]map[string]interface{}", opt.fullPropName))
-			}
+// hasProperty 返回属性值或者具有 key 前缀的属性值是否存在，前缀匹配只检查属性名，
+// 不会将前缀下的属性标记为已读取，这样严格模式仍然能够报告其中拼写错误的属性。
+func hasProperty(p Properties, key string) bool {
+	if key == "" {
+		return true
+	}
+	if _, ok := lookupProperty(p, key); ok {
+		return true
+	}
+	prefix := strings.ToLower(key)
+	for k := range p.GetProperties() {
+		if _, ok := relaxedPrefixMatch(k, prefix); ok {
+			return true
 		}
-	default:
-		panic(errors.New(opt.fieldName + " unsupported type " + v.Kind().String()))
 	}
+	return false
+}
+
+// joinPropertyKey 使用 . 连接属性名
+func joinPropertyKey(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// flattenMapKeys 返回 map 的键，flatten 为 true 时展开嵌套的 map，如 a.b。
+func flattenMapKeys(prefix string, m map[string]interface{}, flatten bool) []string {
+	var result []string
+	for k, v := range m {
+		k = joinPropertyKey(prefix, k)
+		if sub, err := cast.ToStringMapE(v); flatten && err == nil && isContainer(v) {
+			result = append(result, flattenMapKeys(k, sub, flatten)...)
+		} else {
+			result = append(result, k)
+		}
+	}
+	return result
 }
 
 // BindProperty 根据类型获取属性值，属性名称统一转成小写。
@@ -555,6 +892,11 @@ func (p *defaultProperties) BindProperty(key string, i interface{}) {
 
 // BindPropertyIf 根据类型获取属性值，属性名称统一转成小写。
 func (p *defaultProperties) BindPropertyIf(key string, i interface{}, allAccess bool) {
+	bindProperty(p, key, i, allAccess, nil)
+}
+
+// bindProperty 根据类型获取属性值，converters 为 nil 时使用全局的类型转换器。
+func bindProperty(p Properties, key string, i interface{}, allAccess bool, converters *converterRegistry) {
 
 	v := reflect.ValueOf(i)
 	if v.Kind() != reflect.Ptr {
@@ -567,9 +909,16 @@ func (p *defaultProperties) BindPropertyIf(key string, i interface{}, allAccess
 		s = t.Elem().Name()
 	}
 
+	val := &validation{}
 	bindValue(p, v.Elem(), key, nil, bindOption{
 		fieldName:    s,
 		fullPropName: key,
 		allAccess:    allAccess,
+		converters:   converters,
+		validation:   val,
 	})
+
+	if err := val.err(); err != nil {
+		panic(err)
+	}
 }
@@ -759,3 +759,341 @@ func TestDefaultProperties_StringMapString(t *testing.T) {
 		assert.Equal(t, dbConfig2.DB["d1"].DB, "db1")
 	})
 }
+
+type logLevel int
+
+func (l *logLevel) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "debug":
+		*l = 1
+	case "info":
+		*l = 2
+	default:
+		return fmt.Errorf("unknown level %s", text)
+	}
+	return nil
+}
+
+type tlsConfig struct {
+	Cert string `value:"${cert}"`
+	Key  string `value:"${key:=server.key}"`
+}
+
+type routeConfig struct {
+	Path     string   `value:"${path}"`
+	Backends []string `value:"${backends}"`
+}
+
+type listenerConfig struct {
+	Port   int           `value:"${port}"`
+	TLS    *tlsConfig    `value:"${tls}"`
+	Routes []routeConfig `value:"${routes:=}"`
+}
+
+type bindTargetConfig struct {
+	Timeout  *int                      `value:"${timeout}"`
+	Retries  *int                      `value:"${retries:=3}"`
+	Ratios   []float64                 `value:"${ratios}"`
+	Weights  []float32                 `value:"${weights:=0.5, 1.5}"`
+	Level    logLevel                  `value:"${level}"`
+	Levels   []logLevel                `value:"${levels:=debug,info}"`
+	Extra    interface{}               `value:"${extra}"`
+	Groups   map[string][]int          `value:"${groups}"`
+	Limits   map[string]int            `value:"${limits}"`
+	Listener map[string]listenerConfig `value:"${listeners}"`
+}
+
+func TestDefaultProperties_BindTargets(t *testing.T) {
+
+	t.Run("properties", func(t *testing.T) {
+		p := SpringCore.NewDefaultProperties()
+		p.SetProperty("timeout", 30)
+		p.SetProperty("ratios", "0.1, 0.2")
+		p.SetProperty("level", "INFO")
+		p.SetProperty("extra.a", "1")
+		p.SetProperty("groups.a[0]", 1)
+		p.SetProperty("groups.a[1]", 2)
+		p.SetProperty("groups.b", "3,4")
+		p.SetProperty("limits.read", "10")
+		p.SetProperty("limits.write", "20")
+		p.SetProperty("listeners.http.port", 80)
+		p.SetProperty("listeners.http.routes[0].path", "/")
+		p.SetProperty("listeners.http.routes[0].backends[0]", "a")
+		p.SetProperty("listeners.http.routes[0].backends[1]", "b")
+		p.SetProperty("listeners.http.routes[1].path", "/api")
+		p.SetProperty("listeners.http.routes[1].backends", "c")
+		p.SetProperty("listeners.https.port", 443)
+		p.SetProperty("listeners.https.tls.cert", "server.crt")
+
+		var c bindTargetConfig
+		p.BindProperty("", &c)
+
+		assert.Equal(t, *c.Timeout, 30)
+		assert.Equal(t, *c.Retries, 3)
+		assert.Equal(t, c.Ratios, []float64{0.1, 0.2})
+		assert.Equal(t, c.Weights, []float32{0.5, 1.5})
+		assert.Equal(t, c.Level, logLevel(2))
+		assert.Equal(t, c.Levels, []logLevel{1, 2})
+		assert.Equal(t, c.Extra, map[string]interface{}{"extra.a": "1"})
+		assert.Equal(t, c.Groups, map[string][]int{"a": {1, 2}, "b": {3, 4}})
+		assert.Equal(t, c.Limits, map[string]int{"read": 10, "write": 20})
+
+		http := c.Listener["http"]
+		assert.Equal(t, http.Port, 80)
+		assert.Equal(t, http.TLS == nil, true)
+		assert.Equal(t, http.Routes, []routeConfig{
+			{Path: "/", Backends: []string{"a", "b"}},
+			{Path: "/api", Backends: []string{"c"}},
+		})
+
+		https := c.Listener["https"]
+		assert.Equal(t, *https.TLS, tlsConfig{Cert: "server.crt", Key: "server.key"})
+		assert.Equal(t, https.Routes, []routeConfig{})
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		p := SpringCore.NewDefaultProperties()
+		p.ReadProperties(strings.NewReader(`
+ratios: [0.5, 1]
+level: debug
+extra: [1, 2]
+groups:
+  a: [5]
+limits:
+  read: 1
+listeners:
+  grpc:
+    port: 9090
+    tls:
+      cert: grpc.crt
+    routes:
+      - path: /grpc
+        backends: [x, z]
+`), "yaml")
+
+		var c bindTargetConfig
+		p.BindProperty("", &c)
+
+		assert.Equal(t, c.Timeout == nil, true)
+		assert.Equal(t, c.Ratios, []float64{0.5, 1})
+		assert.Equal(t, c.Extra, []interface{}{1, 2})
+		assert.Equal(t, c.Groups, map[string][]int{"a": {5}})
+		assert.Equal(t, c.Listener, map[string]listenerConfig{
+			"grpc": {
+				Port:   9090,
+				TLS:    &tlsConfig{Cert: "grpc.crt", Key: "server.key"},
+				Routes: []routeConfig{{Path: "/grpc", Backends: []string{"x", "z"}}},
+			},
+		})
+	})
+
+	t.Run("error", func(t *testing.T) {
+		p := SpringCore.NewDefaultProperties()
+		p.SetPropertyWithOrigin("level", "trace", SpringCore.PropertyOrigin{Source: "a.properties", Line: 1})
+
+		var l struct {
+			Level logLevel `value:"${level}"`
+		}
+		assert.Panic(t, func() {
+			p.BindProperty("", &l)
+		}, `property value level isn't SpringCore_test.logLevel type: unknown level trace \(from a.properties:1\)`)
+
+		p.SetPropertyWithOrigin("ratios", "a,b", SpringCore.PropertyOrigin{Source: "a.properties", Line: 2})
+		var r struct {
+			Ratios []float64 `value:"${ratios}"`
+		}
+		assert.Panic(t, func() {
+			p.BindProperty("", &r)
+		}, `property value ratios isn't \[\]float64 type \(from a.properties:2\)`)
+	})
+}
+
+func TestDefaultProperties_Placeholder(t *testing.T) {
+
+	p := SpringCore.NewDefaultProperties()
+	p.SetProperty("host", "localhost")
+	p.SetProperty("port", 8080)
+	p.SetProperty("url", "http://${host}:${port}/api")
+	p.SetProperty("port-ref", "${port}")
+	p.SetProperty("def", "${undefined:=9090}")
+	p.SetProperty("nested-def", "${a:=${b:=c}}")
+	p.SetProperty("nested-ref", "${a:=${host}}")
+	p.SetProperty("escape", `\${host}:${port}`)
+	p.SetProperty("cycle-a", "${cycle-b}")
+	p.SetProperty("cycle-b", "x${cycle-c}")
+	p.SetProperty("cycle-c", "${cycle-a}")
+	p.SetProperty("missing", "${undefined}")
+
+	assert.Equal(t, p.GetProperty("url"), "http://localhost:8080/api")
+	assert.Equal(t, p.GetProperty("port-ref"), 8080)
+	assert.Equal(t, p.GetProperty("def"), "9090")
+	assert.Equal(t, p.GetProperty("nested-def"), "c")
+	assert.Equal(t, p.GetProperty("nested-ref"), "localhost")
+	assert.Equal(t, p.GetProperty("escape"), "${host}:8080")
+
+	assert.Panic(t, func() {
+		p.GetProperty("cycle-a")
+	}, "found circular property reference: cycle-a -> cycle-b -> cycle-c -> cycle-a")
+
+	assert.Panic(t, func() {
+		p.GetProperty("missing")
+	}, "property \"undefined\" not config")
+
+	t.Run("priority", func(t *testing.T) {
+		p1 := SpringCore.NewDefaultProperties()
+		p1.SetProperty("url", "http://${host}:${port}")
+		p1.SetProperty("port", 9090)
+
+		p2 := SpringCore.NewDefaultProperties()
+		p2.SetProperty("host", "127.0.0.1")
+		p2.SetProperty("port", 8080)
+
+		l := SpringCore.NewPriorityProperties(p1, p2)
+		assert.Equal(t, l.GetProperty("url"), "http://127.0.0.1:9090")
+	})
+
+	t.Run("bind", func(t *testing.T) {
+		type Config struct {
+			Url  string `value:"${url}"`
+			Host string `value:"${db.host:=${host}}"`
+			Port int    `value:"${db.port:=${undefined:=3306}}"`
+		}
+
+		var c Config
+		p.BindProperty("", &c)
+		assert.Equal(t, c.Url, "http://localhost:8080/api")
+		assert.Equal(t, c.Host, "localhost")
+		assert.Equal(t, c.Port, 3306)
+	})
+}
+
+func TestDefaultProperties_RelaxedBinding(t *testing.T) {
+
+	assert.Equal(t, SpringCore.CanonicalPropertyKey("web.server.maxIdleConns"), "web.server.max-idle-conns")
+	assert.Equal(t, SpringCore.CanonicalPropertyKey("web.server.max_idle_conns"), "web.server.max-idle-conns")
+	assert.Equal(t, SpringCore.CanonicalPropertyKey("web.server.max-idle-conns"), "web.server.max-idle-conns")
+	assert.Equal(t, SpringCore.EnvToPropertyKey("WEB_SERVER_PORT"), "web.server.port")
+	assert.Equal(t, SpringCore.EnvToPropertyKey("spring.profile"), "spring.profile")
+
+	p := SpringCore.NewDefaultProperties()
+	p.SetProperty("db.max-idle-conns", 10)
+	p.SetProperty("web.server.port", 8080)
+	p.SetProperty("db.headers.x-request-id", "abc")
+
+	assert.Equal(t, p.GetProperty("db.maxIdleConns"), 10)
+	assert.Equal(t, p.GetProperty("db.max_idle_conns"), 10)
+	assert.Equal(t, p.GetProperty("db.max-idle-conns"), 10)
+	assert.Equal(t, p.GetProperty("WEB_SERVER_PORT"), 8080)
+	assert.Equal(t, p.GetPrefixProperties("DB.Headers"), map[string]interface{}{
+		"db.headers.x-request-id": "abc",
+	})
+
+	type DBConfig struct {
+		MaxIdleConns int               `value:"${maxIdleConns}"`
+		Headers      map[string]string `value:"${headers}"`
+	}
+
+	var c DBConfig
+	p.BindProperty("db", &c)
+	assert.Equal(t, c.MaxIdleConns, 10)
+	assert.Equal(t, c.Headers, map[string]string{"x-request-id": "abc"})
+}
+
+func TestDefaultProperties_PropertyOrigin(t *testing.T) {
+
+	p := SpringCore.NewDefaultProperties()
+	origin := SpringCore.PropertyOrigin{Layer: "app-config", Source: "application.properties", Line: 3}
+	p.SetPropertyWithOrigin("db.max-idle-conns", "abc", origin)
+	p.SetPropertyWithOrigin("db.servers", []interface{}{
+		map[string]interface{}{"port": "x"},
+	}, origin)
+	p.SetProperty("db.port", 3306)
+
+	o, ok := p.GetPropertyOrigin("db.maxIdleConns")
+	assert.Equal(t, ok, true)
+	assert.Equal(t, o, origin)
+	assert.Equal(t, o.String(), "application.properties:3 [app-config]")
+
+	_, ok = p.GetPropertyOrigin("db.port")
+	assert.Equal(t, ok, false)
+
+	// SetProperty 会清除原有的来源信息
+	p.SetProperty("db.max-idle-conns", 10)
+	_, ok = p.GetPropertyOrigin("db.max-idle-conns")
+	assert.Equal(t, ok, false)
+
+	p.SetPropertyWithOrigin("db.max-idle-conns", "abc", origin)
+	assert.Panic(t, func() {
+		var i int
+		p.BindProperty("db.max-idle-conns", &i)
+	}, `property value db.max-idle-conns isn't int type \(from application.properties:3 \[app-config\]\)`)
+
+	type Server struct {
+		Port int `value:"${port}"`
+	}
+
+	assert.Panic(t, func() {
+		var servers []Server
+		p.BindProperty("db.servers", &servers)
+	}, `property value db.servers\[0\].port isn't int type \(from application.properties:3 \[app-config\]\)`)
+
+	pp := SpringCore.NewPriorityProperties(SpringCore.NewDefaultProperties(), p)
+	pp.SetPropertyWithOrigin("db.port", 3307, SpringCore.PropertyOrigin{Layer: "api"})
+	o, _ = pp.GetPropertyOrigin("db.port")
+	assert.Equal(t, o.Layer, "api")
+	o, _ = pp.GetPropertyOrigin("db.max-idle-conns")
+	assert.Equal(t, o, origin)
+}
+
+func TestDefaultProperties_UnusedProperties(t *testing.T) {
+
+	p := SpringCore.NewDefaultProperties()
+	p.SetProperty("web.server.port", 8080)
+	p.SetProperty("web.sever.host", "localhost")
+	p.SetProperty("db.url", "mysql://${db.host}")
+	p.SetProperty("db.host", "127.0.0.1")
+	p.SetProperty("db.headers.x-request-id", "abc")
+	p.SetProperty("mq.url", "amqp://${mq.host}")
+	p.SetProperty("mq.host", "127.0.0.1")
+
+	type DBConfig struct {
+		Url     string            `value:"${url}"`
+		Headers map[string]string `value:"${headers}"`
+	}
+
+	var c DBConfig
+	p.BindProperty("db", &c)
+	assert.Equal(t, p.GetProperty("WEB_SERVER_PORT"), 8080)
+
+	// 引用的属性也被标记为已读取，没有被读取的属性引用的属性不被标记
+	assert.Equal(t, p.GetUnusedProperties(), []string{"mq.host", "mq.url", "web.sever.host"})
+
+	pp := SpringCore.NewPriorityProperties(SpringCore.NewDefaultProperties(), p)
+	pp.SetProperty("mq.url", "amqp://localhost")
+	pp.SetProperty("api.key", "abc")
+	assert.Equal(t, pp.GetProperty("mq.url"), "amqp://localhost")
+
+	// 被高优先级层覆盖的属性不返回
+	assert.Equal(t, pp.GetUnusedProperties(), []string{"api.key", "mq.host", "web.sever.host"})
+}
+
+func TestDefaultProperties_UnusedPointerProperties(t *testing.T) {
+
+	p := SpringCore.NewDefaultProperties()
+	p.SetProperty("tls.cert", "a.pem")
+	p.SetProperty("tls.kye", "a.key")
+
+	type TLSConfig struct {
+		Cert string `value:"${cert}"`
+		Key  string `value:"${key:=}"`
+	}
+
+	var c struct {
+		TLS *TLSConfig `value:"${tls}"`
+	}
+	p.BindProperty("", &c)
+	assert.Equal(t, c.TLS.Cert, "a.pem")
+
+	// 判断可选的指针字段是否存在时不标记前缀下的属性
+	assert.Equal(t, p.GetUnusedProperties(), []string{"tls.kye"})
+}
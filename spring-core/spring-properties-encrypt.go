@@ -0,0 +1,270 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringCore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// 加密的属性值形如 ENC(base64...)，整个属性值都是加密内容时才会被解密，读取属性值
+// 时透明解密，包括 GetProperty、属性绑定和引用解析，GetProperties 返回的仍是密文。
+
+// MaskedPropertyValue 加密属性值在日志和配置输出中的替代值
+const MaskedPropertyValue = "******"
+
+// PropertyDecryptor 属性值解密器
+type PropertyDecryptor interface {
+	// Decrypt 解密 ENC(...) 括号中的内容，返回明文。
+	Decrypt(cipherText string) (string, error)
+}
+
+// PropertyEncryptor 属性值加密器，用于生成配置文件中的加密属性值
+type PropertyEncryptor interface {
+	// Encrypt 加密明文，返回 ENC(...) 形式的属性值。
+	Encrypt(plainText string) (string, error)
+}
+
+// propertyDecryptor 全局的属性值解密器
+var propertyDecryptor PropertyDecryptor
+
+// SetPropertyDecryptor 设置全局的属性值解密器，为 nil 时读取加密属性值会报错。
+func SetPropertyDecryptor(d PropertyDecryptor) {
+	propertyDecryptor = d
+}
+
+// GetPropertyDecryptor 返回全局的属性值解密器
+func GetPropertyDecryptor() PropertyDecryptor {
+	return propertyDecryptor
+}
+
+// IsEncryptedValue 返回是否是 ENC(...) 形式的加密属性值
+func IsEncryptedValue(v interface{}) bool {
+	s, ok := v.(string)
+	return ok && len(s) > 5 && strings.HasPrefix(s, "ENC(") && strings.HasSuffix(s, ")")
+}
+
+// MaskPropertyValue 返回用于日志和配置输出的属性值，加密属性值被替换为 ******。
+func MaskPropertyValue(v interface{}) interface{} {
+	if IsEncryptedValue(v) {
+		return MaskedPropertyValue
+	}
+	return v
+}
+
+// IsSensitiveProperty 返回属性值是否需要在日志和诊断输出中隐藏，包括加密的属性值、
+// 来源被标记为敏感的属性值，以及通过引用使用了这些属性值的属性值。它只检查未经解析的属性值，不会解密属性值，
+// 也不会将属性标记为已读取。
+func IsSensitiveProperty(p Properties, key string) bool {
+	return newSensitiveChecker(p).isSensitive(strings.ToLower(key), nil)
+}
+
+// MaskProperties 返回用于日志和诊断输出的所有未经解析的属性值，敏感的属性值被替换
+// 为 ******，见 IsSensitiveProperty。
+func MaskProperties(p Properties) map[string]interface{} {
+	c := newSensitiveChecker(p)
+	result := make(map[string]interface{}, len(c.raw))
+	for k, v := range c.raw {
+		if c.isSensitive(k, nil) {
+			v = MaskedPropertyValue
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// sensitiveChecker 判断属性值是否敏感，属性名宽松匹配。
+type sensitiveChecker struct {
+	p       Properties             // 用于获取属性值的来源
+	raw     map[string]interface{} // 未经解析的属性值
+	uniform map[string]string      // 统一格式的属性名 -> 属性名
+}
+
+// newSensitiveChecker sensitiveChecker 的构造函数
+func newSensitiveChecker(p Properties) *sensitiveChecker {
+	c := &sensitiveChecker{
+		p:       p,
+		raw:     p.GetProperties(),
+		uniform: make(map[string]string),
+	}
+	for k := range c.raw {
+		c.uniform[uniformPropertyKey(k)] = k
+	}
+	return c
+}
+
+// lookup 返回和 key 宽松匹配的属性名及其未经解析的属性值
+func (c *sensitiveChecker) lookup(key string) (string, interface{}, bool) {
+	if v, ok := c.raw[key]; ok {
+		return key, v, true
+	}
+	if k, ok := c.uniform[uniformPropertyKey(key)]; ok {
+		return k, c.raw[k], true
+	}
+	return "", nil, false
+}
+
+// isSensitive 返回 key 的属性值是否敏感，chain 用于避免循环引用。
+func (c *sensitiveChecker) isSensitive(key string, chain []string) bool {
+	for _, k := range chain {
+		if k == key {
+			return false
+		}
+	}
+	k, v, ok := c.lookup(key)
+	if !ok {
+		return false
+	}
+	if origin, _ := c.p.GetPropertyOrigin(k); origin.Sensitive {
+		return true
+	}
+	return c.isSensitiveValue(v, append(chain[:len(chain):len(chain)], k))
+}
+
+// isSensitiveValue 返回属性值是否是加密的属性值，或者引用了敏感的属性值，包括引用
+// 的默认值。
+func (c *sensitiveChecker) isSensitiveValue(v interface{}, chain []string) bool {
+
+	if IsEncryptedValue(v) {
+		return true
+	}
+
+	s, ok := v.(string)
+	if !ok {
+		return false
+	}
+
+	for i := strings.Index(s, "${"); i >= 0; {
+		end, err := matchPlaceholder(s, i)
+		if err != nil {
+			return false
+		}
+
+		key, def, hasDef := s[i+2:end], "", false
+		if j := strings.Index(key, ":="); j >= 0 {
+			key, def, hasDef = key[:j], key[j+2:], true
+		}
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		if _, _, ok := c.lookup(key); ok {
+			if c.isSensitive(key, chain) {
+				return true
+			}
+		} else if hasDef && c.isSensitiveValue(def, chain) {
+			return true
+		}
+
+		next := strings.Index(s[end+1:], "${")
+		if next < 0 {
+			break
+		}
+		i = end + 1 + next
+	}
+	return false
+}
+
+// decryptValue 解密 key 的加密属性值
+func decryptValue(key string, s string) (string, error) {
+	if propertyDecryptor == nil {
+		return "", fmt.Errorf("property \"%s\" is encrypted but no PropertyDecryptor set", key)
+	}
+	v, err := propertyDecryptor.Decrypt(s[4 : len(s)-1])
+	if err != nil {
+		return "", fmt.Errorf("decrypt property \"%s\" error: %v", key, err)
+	}
+	return v, nil
+}
+
+// AESGCMDecryptor 使用 AES-GCM 算法的属性值加解密器，密文为 base64 编码的 nonce 和加密结果。
+type AESGCMDecryptor struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCMDecryptor AESGCMDecryptor 的构造函数，key 的长度必须是 16、24 或者 32 字节。
+func NewAESGCMDecryptor(key []byte) (*AESGCMDecryptor, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &AESGCMDecryptor{aead: aead}, nil
+}
+
+// NewAESGCMDecryptorFromFile 使用文件中 base64 编码的秘钥创建 AESGCMDecryptor
+func NewAESGCMDecryptorFromFile(filename string) (*AESGCMDecryptor, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return newAESGCMDecryptorFromString(string(b))
+}
+
+// NewAESGCMDecryptorFromEnv 使用环境变量中 base64 编码的秘钥创建 AESGCMDecryptor
+func NewAESGCMDecryptorFromEnv(name string) (*AESGCMDecryptor, error) {
+	s, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, fmt.Errorf("environment variable %s not set", name)
+	}
+	return newAESGCMDecryptorFromString(s)
+}
+
+// newAESGCMDecryptorFromString 使用 base64 编码的秘钥创建 AESGCMDecryptor
+func newAESGCMDecryptorFromString(s string) (*AESGCMDecryptor, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("invalid aes key: %v", err)
+	}
+	return NewAESGCMDecryptor(key)
+}
+
+// Encrypt 加密明文，返回 ENC(...) 形式的属性值。
+func (d *AESGCMDecryptor) Encrypt(plainText string) (string, error) {
+	nonce := make([]byte, d.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	b := d.aead.Seal(nonce, nonce, []byte(plainText), nil)
+	return "ENC(" + base64.StdEncoding.EncodeToString(b) + ")", nil
+}
+
+// Decrypt 解密 ENC(...) 括号中的内容，返回明文。
+func (d *AESGCMDecryptor) Decrypt(cipherText string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(cipherText)
+	if err != nil {
+		return "", err
+	}
+	n := d.aead.NonceSize()
+	if len(b) < n {
+		return "", errors.New("cipher text too short")
+	}
+	plain, err := d.aead.Open(nil, b[:n], b[n:], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
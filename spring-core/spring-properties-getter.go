@@ -0,0 +1,326 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringCore
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/spf13/cast"
+)
+
+// ErrPropertyNotFound 属性值不存在，GetXxxPropertyE 系列方法用它区分未设置和零值。
+var ErrPropertyNotFound = errors.New("not config")
+
+// PropertyError 读取属性值时发生的错误，包含属性名和属性值的来源。
+type PropertyError struct {
+	Key    string          // 属性名
+	Origin *PropertyOrigin // 属性值的来源，nil 表示未知
+	Err    error           // 错误原因，如 ErrPropertyNotFound
+}
+
+// Error 返回形如 property "a" isn't int type (from a.properties:3) 的错误信息
+func (e *PropertyError) Error() string {
+	s := fmt.Sprintf("property \"%s\" %v", e.Key, e.Err)
+	if e.Origin != nil {
+		s += fmt.Sprintf(" (from %s)", e.Origin)
+	}
+	return s
+}
+
+// Unwrap 返回错误原因
+func (e *PropertyError) Unwrap() error {
+	return e.Err
+}
+
+// IsPropertyNotFound 返回是否是属性值不存在的错误
+func IsPropertyNotFound(err error) bool {
+	e, ok := err.(*PropertyError)
+	return ok && e.Err == ErrPropertyNotFound
+}
+
+// newPropertyError 返回 key 对应的 PropertyError，自动填充属性值的来源。
+func newPropertyError(p Properties, key string, err error) error {
+	e := &PropertyError{Key: key, Err: err}
+	if origin, ok := p.GetPropertyOrigin(key); ok {
+		e.Origin = &origin
+	}
+	return e
+}
+
+// getPropertyE 返回解析之后的属性值，属性值不存在或者解析失败时返回 PropertyError。
+func getPropertyE(p Properties, key string) (interface{}, error) {
+	v, ok := lookupProperty(p, key)
+	if !ok {
+		return nil, &PropertyError{Key: key, Err: ErrPropertyNotFound}
+	}
+	r, err := resolveValue(p, v, []string{strings.ToLower(key)})
+	if err != nil {
+		return nil, newPropertyError(p, key, fmt.Errorf("can't be resolved: %v", err))
+	}
+	return r, nil
+}
+
+// convertPropertyE 返回转换为指定类型的属性值，转换失败时返回包含类型名的错误。
+func convertPropertyE(p Properties, key string, typeName string, fn func(interface{}) (interface{}, error)) (interface{}, error) {
+	v, err := getPropertyE(p, key)
+	if err != nil {
+		return nil, err
+	}
+	r, err := fn(v)
+	if err != nil {
+		return nil, newPropertyError(p, key, fmt.Errorf("isn't %s type", typeName))
+	}
+	return r, nil
+}
+
+// getBoolPropertyE 返回布尔型的属性值
+func getBoolPropertyE(p Properties, key string) (bool, error) {
+	v, err := convertPropertyE(p, key, "bool", func(v interface{}) (interface{}, error) {
+		return cast.ToBoolE(v)
+	})
+	if err != nil {
+		return false, err
+	}
+	return v.(bool), nil
+}
+
+// getIntPropertyE 返回有符号整型的属性值
+func getIntPropertyE(p Properties, key string) (int64, error) {
+	v, err := convertPropertyE(p, key, "int", func(v interface{}) (interface{}, error) {
+		return cast.ToInt64E(v)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return v.(int64), nil
+}
+
+// getUintPropertyE 返回无符号整型的属性值，负数是非法的。
+func getUintPropertyE(p Properties, key string) (uint64, error) {
+	v, err := convertPropertyE(p, key, "uint", func(v interface{}) (interface{}, error) {
+		return cast.ToUint64E(v)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return v.(uint64), nil
+}
+
+// getFloatPropertyE 返回浮点型的属性值
+func getFloatPropertyE(p Properties, key string) (float64, error) {
+	v, err := convertPropertyE(p, key, "float", func(v interface{}) (interface{}, error) {
+		return cast.ToFloat64E(v)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return v.(float64), nil
+}
+
+// getStringPropertyE 返回字符串型的属性值
+func getStringPropertyE(p Properties, key string) (string, error) {
+	v, err := convertPropertyE(p, key, "string", func(v interface{}) (interface{}, error) {
+		return cast.ToStringE(v)
+	})
+	if err != nil {
+		return "", err
+	}
+	return v.(string), nil
+}
+
+// getDurationPropertyE 返回 Duration 类型的属性值
+func getDurationPropertyE(p Properties, key string) (time.Duration, error) {
+	v, err := convertPropertyE(p, key, "time.Duration", func(v interface{}) (interface{}, error) {
+		return cast.ToDurationE(v)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return v.(time.Duration), nil
+}
+
+// getTimePropertyE 返回 Time 类型的属性值
+func getTimePropertyE(p Properties, key string) (time.Time, error) {
+	v, err := convertPropertyE(p, key, "time.Time", func(v interface{}) (interface{}, error) {
+		return cast.ToTimeE(v)
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	return v.(time.Time), nil
+}
+
+// getStringSlicePropertyE 返回字符串列表类型的属性值，字符串按照逗号切割并去掉空白。
+func getStringSlicePropertyE(p Properties, key string) ([]string, error) {
+	v, err := convertPropertyE(p, key, "[]string", func(v interface{}) (interface{}, error) {
+		if s, ok := v.(string); ok {
+			result := make([]string, 0)
+			for _, item := range strings.Split(s, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					result = append(result, item)
+				}
+			}
+			return result, nil
+		}
+		return cast.ToStringSliceE(v)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]string), nil
+}
+
+// getStringMapPropertyE 返回 map[string]string 类型的属性值，由属性名以 key 为前缀的
+// 属性值组成，map 的键是去掉前缀之后的属性名。
+func getStringMapPropertyE(p Properties, key string) (map[string]string, error) {
+
+	if _, ok := lookupProperty(p, key); ok {
+		v, err := convertPropertyE(p, key, "map[string]string", func(v interface{}) (interface{}, error) {
+			return cast.ToStringMapStringE(v)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return v.(map[string]string), nil
+	}
+
+	prefix := strings.ToLower(key)
+
+	var keys []string
+	for k := range p.GetProperties() {
+		if s, ok := relaxedPrefixMatch(k, prefix); ok && s != prefix {
+			keys = append(keys, k)
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, &PropertyError{Key: key, Err: ErrPropertyNotFound}
+	}
+
+	sort.Strings(keys)
+	result := make(map[string]string)
+	for _, k := range keys {
+		s, err := getStringPropertyE(p, k)
+		if err != nil {
+			return nil, err
+		}
+		name, _ := relaxedPrefixMatch(k, prefix)
+		result[strings.TrimPrefix(name, prefix+".")] = s
+	}
+	return result, nil
+}
+
+// getByteSizePropertyE 返回字节数，属性值的格式见 parseByteSize。
+func getByteSizePropertyE(p Properties, key string) (int64, error) {
+	v, err := convertPropertyE(p, key, "byte size", func(v interface{}) (interface{}, error) {
+		if s, ok := v.(string); ok {
+			return parseByteSize(s)
+		}
+		return cast.ToInt64E(v)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return v.(int64), nil
+}
+
+// getURLPropertyE 返回 URL 类型的属性值，URL 必须包含 scheme。
+func getURLPropertyE(p Properties, key string) (*url.URL, error) {
+	v, err := convertPropertyE(p, key, "url", func(v interface{}) (interface{}, error) {
+		s, err := cast.ToStringE(v)
+		if err != nil {
+			return nil, err
+		}
+		return parseURL(s)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*url.URL), nil
+}
+
+// byteSizeUnits 字节数的单位，使用 1024 进制，单位不区分大小写。
+var byteSizeUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"k":   1 << 10,
+	"kb":  1 << 10,
+	"kib": 1 << 10,
+	"m":   1 << 20,
+	"mb":  1 << 20,
+	"mib": 1 << 20,
+	"g":   1 << 30,
+	"gb":  1 << 30,
+	"gib": 1 << 30,
+	"t":   1 << 40,
+	"tb":  1 << 40,
+	"tib": 1 << 40,
+}
+
+// parseByteSize 解析字节数，如 1024、512B、64KB、1.5MB、2GiB，数字和单位之间可以
+// 有空白，单位使用 1024 进制并且不区分大小写，没有单位时表示字节。
+func parseByteSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return !(unicode.IsDigit(r) || r == '.')
+	})
+	if i < 0 {
+		i = len(s)
+	}
+
+	unit, ok := byteSizeUnits[strings.ToLower(strings.TrimSpace(s[i:]))]
+	if !ok || i == 0 {
+		return 0, fmt.Errorf("invalid byte size \"%s\"", s)
+	}
+
+	if n, err := strconv.ParseInt(s[:i], 10, 64); err == nil {
+		if n > math.MaxInt64/unit {
+			return 0, fmt.Errorf("byte size \"%s\" overflows int64", s)
+		}
+		return n * unit, nil
+	}
+
+	f, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size \"%s\"", s)
+	}
+	if f*float64(unit) >= math.MaxInt64 {
+		return 0, fmt.Errorf("byte size \"%s\" overflows int64", s)
+	}
+	return int64(f * float64(unit)), nil
+}
+
+// parseURL 解析 URL，URL 必须包含 scheme。
+func parseURL(s string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" {
+		return nil, fmt.Errorf("url \"%s\" has no scheme", s)
+	}
+	return u, nil
+}
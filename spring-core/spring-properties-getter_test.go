@@ -0,0 +1,152 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringCore_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-spring/go-spring/spring-core"
+	"github.com/magiconair/properties/assert"
+)
+
+func TestProperties_GetPropertyE(t *testing.T) {
+
+	origin := SpringCore.PropertyOrigin{Layer: "app-config", Source: "application.properties", Line: 3}
+
+	p := SpringCore.NewDefaultProperties()
+	p.SetProperty("zero", 0)
+	p.SetProperty("int", "${zero}")
+	p.SetPropertyWithOrigin("bad", "abc", origin)
+	p.SetProperty("bool", "true")
+	p.SetProperty("float", "0.5")
+	p.SetProperty("timeout", "3s")
+	p.SetProperty("date", "2019-11-11")
+	p.SetProperty("hosts", "a, b,,c")
+	p.SetProperty("ports[0]", 80)
+	p.SetProperty("ports[1]", 443)
+	p.SetProperty("labels.app", "demo")
+	p.SetProperty("labels.tier", "${int}")
+	p.SetProperty("buffer", "1.5 MB")
+	p.SetProperty("endpoint", "https://example.com:8443/api")
+	p.SetPropertyWithOrigin("ref", "${missing}", SpringCore.PropertyOrigin{Source: "application.properties", Line: 4})
+
+	pp := SpringCore.NewPriorityProperties(SpringCore.NewDefaultProperties(), p)
+
+	for _, p := range []SpringCore.Properties{p, pp} {
+
+		i, err := p.GetIntPropertyE("int")
+		assert.Equal(t, err, nil)
+		assert.Equal(t, i, int64(0))
+
+		u, err := p.GetUintPropertyE("zero")
+		assert.Equal(t, err, nil)
+		assert.Equal(t, u, uint64(0))
+
+		b, err := p.GetBoolPropertyE("bool")
+		assert.Equal(t, err, nil)
+		assert.Equal(t, b, true)
+
+		f, err := p.GetFloatPropertyE("float")
+		assert.Equal(t, err, nil)
+		assert.Equal(t, f, 0.5)
+
+		s, err := p.GetStringPropertyE("int")
+		assert.Equal(t, err, nil)
+		assert.Equal(t, s, "0")
+
+		d, err := p.GetDurationPropertyE("timeout")
+		assert.Equal(t, err, nil)
+		assert.Equal(t, d, 3*time.Second)
+
+		tm, err := p.GetTimePropertyE("date")
+		assert.Equal(t, err, nil)
+		assert.Equal(t, tm.Format("2006-01-02"), "2019-11-11")
+
+		ss, err := p.GetStringSlicePropertyE("hosts")
+		assert.Equal(t, err, nil)
+		assert.Equal(t, ss, []string{"a", "b", "c"})
+
+		ss, err = p.GetStringSlicePropertyE("ports")
+		assert.Equal(t, err, nil)
+		assert.Equal(t, ss, []string{"80", "443"})
+
+		m, err := p.GetStringMapPropertyE("labels")
+		assert.Equal(t, err, nil)
+		assert.Equal(t, m, map[string]string{"app": "demo", "tier": "0"})
+
+		n, err := p.GetByteSizePropertyE("buffer")
+		assert.Equal(t, err, nil)
+		assert.Equal(t, n, int64(1572864))
+
+		url, err := p.GetURLPropertyE("endpoint")
+		assert.Equal(t, err, nil)
+		assert.Equal(t, url.Host, "example.com:8443")
+
+		// 区分未设置和零值
+		_, err = p.GetIntPropertyE("missing")
+		assert.Equal(t, err.Error(), `property "missing" not config`)
+		assert.Equal(t, SpringCore.IsPropertyNotFound(err), true)
+
+		_, err = p.GetStringMapPropertyE("missing")
+		assert.Equal(t, SpringCore.IsPropertyNotFound(err), true)
+
+		// 错误信息包含属性名及其来源
+		_, err = p.GetIntPropertyE("bad")
+		assert.Equal(t, err.Error(), `property "bad" isn't int type (from application.properties:3 [app-config])`)
+		assert.Equal(t, SpringCore.IsPropertyNotFound(err), false)
+
+		_, err = p.GetURLPropertyE("bad")
+		assert.Equal(t, err.Error(), `property "bad" isn't url type (from application.properties:3 [app-config])`)
+
+		_, err = p.GetByteSizePropertyE("bool")
+		assert.Equal(t, err.Error(), `property "bool" isn't byte size type`)
+
+		// 解析引用失败的错误同样包含属性名及其来源
+		_, err = p.GetStringPropertyE("ref")
+		assert.Equal(t, err.Error(), `property "ref" can't be resolved: property "missing" not config (from application.properties:4)`)
+		assert.Equal(t, SpringCore.IsPropertyNotFound(err), false)
+		assert.Equal(t, err.(*SpringCore.PropertyError).Key, "ref")
+	}
+}
+
+func TestProperties_GetByteSizePropertyE(t *testing.T) {
+
+	for s, expect := range map[interface{}]int64{
+		1024:    1024,
+		"512":   512,
+		"512B":  512,
+		"64kb":  64 << 10,
+		"64KiB": 64 << 10,
+		"2G":    2 << 30,
+		"1 TB":  1 << 40,
+		"0.5mb": 512 << 10,
+	} {
+		p := SpringCore.NewDefaultProperties()
+		p.SetProperty("size", s)
+		n, err := p.GetByteSizePropertyE("size")
+		assert.Equal(t, err, nil)
+		assert.Equal(t, n, expect)
+	}
+
+	for _, s := range []string{"", "MB", "1.2.3KB", "10PB", "9999999999TB"} {
+		p := SpringCore.NewDefaultProperties()
+		p.SetProperty("size", s)
+		_, err := p.GetByteSizePropertyE("size")
+		assert.Equal(t, err.Error(), `property "size" isn't byte size type`)
+	}
+}
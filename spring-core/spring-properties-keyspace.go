@@ -0,0 +1,347 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringCore
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// 属性名中的下标：
+//   servers[0].host=a   列表 servers 的第 1 个元素的 host 属性；
+//   hosts[1]=b          列表 hosts 的第 2 个元素；
+//   matrix[0][1]=c      嵌套的列表。
+// 读取 servers 时，下标形式的属性值被组装成列表，列表的元素是属性值或者 map，因此
+// .properties 等只能表达键值对的配置文件也可以配置结构体列表。同一个配置层中，下标
+// 形式的属性值覆盖 yaml 等格式的列表中对应的元素；不同配置层之间列表是一个整体，
+// 高优先级层只要定义了列表的任一元素，低优先级层中的整个列表都会被忽略。反过来，
+// 也可以使用下标访问 yaml 等格式的列表中的元素，如 ${servers[0].host}，同样也可以
+// 访问 map 类型的属性值中的元素。
+//
+// 属性名的大小写：属性名统一转成小写存储，同时记录设置属性值时使用的原始属性名，
+// 见 Properties.GetPropertyName。绑定 map 时使用 case:"preserve" 标签可以保留
+// map 的键的大小写，比如 HTTP 头部的名称。yaml 等格式的配置文件在读取时已经丢失了
+// 属性名的大小写，.properties 文件以及通过代码设置的属性名保留大小写。
+
+// indexedRoots 返回属性名中每个下标之前的部分的宽松形式，如 servers[0].ports[1]
+// 返回 servers 和 servers[0].ports。
+func indexedRoots(key string) []string {
+	var result []string
+	for i := 1; i < len(key); i++ {
+		if key[i] == '[' {
+			result = append(result, uniformPropertyKey(key[:i]))
+		}
+	}
+	return result
+}
+
+// listRoot 返回属性值所属的列表的属性名的宽松形式，如 servers[0].host 返回
+// servers，列表类型的属性值返回属性名本身，不属于任何列表时返回 false。
+func listRoot(key string, value interface{}) (string, bool) {
+	if i := strings.Index(key, "["); i > 0 {
+		return uniformPropertyKey(key[:i]), true
+	}
+	if isList(value) {
+		return uniformPropertyKey(key), true
+	}
+	return "", false
+}
+
+// shadowedKeys 返回 next 中被 curr 覆盖的属性名，除了同名的属性之外，curr 中定义
+// 了列表的任一元素时 next 中属于该列表的所有属性都被覆盖。
+func shadowedKeys(curr map[string]interface{}, next map[string]interface{}) map[string]bool {
+
+	roots := make(map[string]bool)
+	for k, v := range curr {
+		if root, ok := listRoot(k, v); ok {
+			roots[root] = true
+		}
+	}
+
+	result := make(map[string]bool)
+	for k, v := range next {
+		if _, ok := curr[k]; ok {
+			result[k] = true
+		} else if root, ok := listRoot(k, v); ok && roots[root] {
+			result[k] = true
+		}
+	}
+	return result
+}
+
+// isList 返回属性值是否是列表，[]byte 不是列表。
+func isList(value interface{}) bool {
+	if value == nil {
+		return false
+	}
+	t := reflect.TypeOf(value)
+	return t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8
+}
+
+// toList 将列表类型的属性值拷贝为 []interface{}，不是列表时返回 nil。
+func toList(value interface{}) []interface{} {
+	if !isList(value) {
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	result := make([]interface{}, v.Len())
+	for i := range result {
+		result[i] = v.Index(i).Interface()
+	}
+	return result
+}
+
+// parseIndex 解析属性名后缀开头的下标，如 [0].host 返回 0 和 .host。
+func parseIndex(s string) (int, string, bool) {
+	if !strings.HasPrefix(s, "[") {
+		return 0, "", false
+	}
+	end := strings.Index(s, "]")
+	if end < 0 {
+		return 0, "", false
+	}
+	i, err := strconv.Atoi(s[1:end])
+	if err != nil || i < 0 {
+		return 0, "", false
+	}
+	rest := s[end+1:]
+	if rest != "" && rest[0] != '.' && rest[0] != '[' {
+		return 0, "", false
+	}
+	return i, rest, true
+}
+
+// indexedRest 返回属性名 k 中从 key 的下标开始的后缀，如 servers[0].host 对于
+// servers 返回 [0].host，root 是 key 的宽松形式。
+func indexedRest(k string, root string) (string, bool) {
+	for i := 1; i < len(k); i++ {
+		if k[i] == '[' && uniformPropertyKey(k[:i]) == root {
+			if _, _, ok := parseIndex(k[i:]); ok {
+				return k[i:], true
+			}
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// indexedKeys 返回 key 的下标形式的属性名，按照属性名排序。
+func (p *defaultProperties) indexedKeys(key string) []string {
+	root := uniformPropertyKey(key)
+	if !p.roots[root] {
+		return nil
+	}
+
+	var result []string
+	for k := range p.properties {
+		if _, ok := indexedRest(k, root); ok {
+			result = append(result, k)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// getIndexedProperty 使用下标形式的属性值组装列表，base 是同名的列表，组装时下标
+// 形式的属性值覆盖 base 中对应的元素，没有下标形式的属性值时返回 false。
+func (p *defaultProperties) getIndexedProperty(key string, base interface{}) (interface{}, bool) {
+
+	keys := p.indexedKeys(key)
+	if len(keys) == 0 {
+		return nil, false
+	}
+
+	root := uniformPropertyKey(key)
+	entries := make(map[string]interface{})
+	for _, k := range keys {
+		rest, _ := indexedRest(k, root)
+		entries[rest] = p.properties[k]
+		p.used.Store(k, true)
+	}
+
+	list, err := buildIndexedList(strings.ToLower(key), toList(base), entries)
+	if err != nil {
+		panic(err)
+	}
+	return list, true
+}
+
+// buildIndexedList 组装列表，entries 的属性名是下标开头的后缀，如 [0].host。
+func buildIndexedList(key string, base []interface{}, entries map[string]interface{}) ([]interface{}, error) {
+
+	groups := make(map[int]map[string]interface{})
+	size := len(base)
+
+	for s, v := range entries {
+		i, rest, _ := parseIndex(s)
+		group, ok := groups[i]
+		if !ok {
+			group = make(map[string]interface{})
+			groups[i] = group
+		}
+		group[rest] = v
+		if i >= size {
+			size = i + 1
+		}
+	}
+
+	result := make([]interface{}, size)
+	copy(result, base)
+
+	for i := 0; i < size; i++ {
+		group, ok := groups[i]
+		if !ok {
+			if i >= len(base) {
+				return nil, fmt.Errorf("property \"%s\" is missing index %d", key, i)
+			}
+			continue
+		}
+
+		elemKey := fmt.Sprintf("%s[%d]", key, i)
+
+		if v, ok := group[""]; ok {
+			if len(group) > 1 {
+				return nil, fmt.Errorf("property \"%s\" has both value and sub properties", elemKey)
+			}
+			result[i] = v
+			continue
+		}
+
+		var (
+			nested = make(map[string]interface{})
+			fields = make(map[string]interface{})
+		)
+
+		for rest, v := range group {
+			if rest[0] == '[' {
+				nested[rest] = v
+			} else {
+				fields[rest[1:]] = v
+			}
+		}
+
+		if len(nested) > 0 && len(fields) > 0 {
+			return nil, fmt.Errorf("property \"%s\" has both list and map elements", elemKey)
+		}
+
+		if len(nested) > 0 {
+			list, err := buildIndexedList(elemKey, toList(result[i]), nested)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = list
+			continue
+		}
+
+		m := make(map[string]interface{})
+		if old, err := cast.ToStringMapE(result[i]); err == nil {
+			for k, v := range old {
+				m[k] = v
+			}
+		}
+		for k, v := range fields {
+			m[k] = v
+		}
+		result[i] = m
+	}
+
+	return result, nil
+}
+
+// parentKey 返回属性名的上一级属性名以及剩余的部分，如 servers[0].host 返回
+// servers[0] 和 .host，servers[0] 返回 servers 和 [0]。
+func parentKey(key string) (string, string, bool) {
+	if i := strings.LastIndexAny(key, ".["); i > 0 {
+		return key[:i], key[i:], true
+	}
+	return "", "", false
+}
+
+// getNestedProperty 访问列表或者 map 类型的属性值中的元素，如 servers[0].host，
+// 从最近的存在的上一级属性值开始访问。
+func (p *defaultProperties) getNestedProperty(key string) (interface{}, bool) {
+	for parent, _, ok := parentKey(key); ok; parent, _, ok = parentKey(parent) {
+		if v, found := p.getLocalProperty(parent); found {
+			if !isContainer(v) {
+				return nil, false
+			}
+			return navigateProperty(v, key[len(parent):])
+		}
+	}
+	return nil, false
+}
+
+// isContainer 返回属性值是否是列表或者 map
+func isContainer(value interface{}) bool {
+	return isList(value) || (value != nil && reflect.TypeOf(value).Kind() == reflect.Map)
+}
+
+// navigateProperty 按照 [0].host 形式的路径访问列表和 map 中的元素，map 的键宽松匹配。
+func navigateProperty(value interface{}, path string) (interface{}, bool) {
+
+	if path == "" {
+		return value, true
+	}
+
+	if i, rest, ok := parseIndex(path); ok {
+		list := toList(value)
+		if i >= len(list) {
+			return nil, false
+		}
+		return navigateProperty(list[i], rest)
+	}
+
+	if path[0] != '.' || !isContainer(value) {
+		return nil, false
+	}
+
+	m, err := cast.ToStringMapE(value)
+	if err != nil {
+		return nil, false
+	}
+
+	path = path[1:]
+
+	// 优先匹配拍平的属性名，如 a.b
+	end := len(path)
+	for end > 0 {
+		for k, v := range m {
+			if uniformPropertyKey(k) == uniformPropertyKey(path[:end]) {
+				return navigateProperty(v, path[end:])
+			}
+		}
+		end = strings.LastIndexAny(path[:end], ".[")
+	}
+	return nil, false
+}
+
+// mapKey 返回属性名 k 去掉前缀 prefix 之后的部分作为 map 的键，标签要求保留大小写
+// 时使用原始的属性名。
+func mapKey(p Properties, prefix string, k string, opt bindOption) string {
+	if opt.preserveCase {
+		n := strings.Count(prefix, ".") + 1
+		if ss := strings.SplitN(p.GetPropertyName(k), ".", n+1); len(ss) > n {
+			return ss[n]
+		}
+	}
+	return strings.TrimPrefix(k, prefix+".")
+}
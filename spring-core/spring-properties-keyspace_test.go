@@ -0,0 +1,183 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringCore_test
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-spring/go-spring/spring-core"
+	"github.com/magiconair/properties/assert"
+)
+
+type IndexedServer struct {
+	Host  string `value:"${host}"`
+	Port  int    `value:"${port:=80}"`
+	Ports []int  `value:"${ports:=1}"`
+}
+
+type IndexedConfig struct {
+	Servers []IndexedServer `value:"${servers}"`
+	Hosts   []string        `value:"${hosts}"`
+}
+
+func TestDefaultProperties_IndexedKeys(t *testing.T) {
+
+	t.Run("properties", func(t *testing.T) {
+		p := SpringCore.NewDefaultProperties()
+		p.ReadProperties(strings.NewReader(`
+servers[0].host=a
+servers[0].ports[0]=8080
+servers[0].ports[1]=8081
+servers[1].host=b
+servers[1].port=9090
+servers[1].ports=9090,9091
+hosts[0]=x
+hosts[1]=y
+`), "properties")
+
+		var c IndexedConfig
+		p.BindProperty("", &c)
+		assert.Equal(t, c, IndexedConfig{
+			Servers: []IndexedServer{
+				{Host: "a", Port: 80, Ports: []int{8080, 8081}},
+				{Host: "b", Port: 9090, Ports: []int{9090, 9091}},
+			},
+			Hosts: []string{"x", "y"},
+		})
+
+		assert.Equal(t, p.GetProperty("hosts"), []interface{}{"x", "y"})
+		assert.Equal(t, p.GetProperty("servers[1]"), map[string]interface{}{"host": "b", "port": "9090", "ports": "9090,9091"})
+		assert.Equal(t, p.GetProperty("servers[0].ports"), []interface{}{"8080", "8081"})
+		assert.Equal(t, p.GetUnusedProperties(), []string(nil))
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		p := SpringCore.NewDefaultProperties()
+		p.ReadProperties(strings.NewReader(`
+servers:
+  - host: a
+    port: 8080
+  - host: b
+url: http://${servers[1].host}:${servers[0].port}
+`), "yaml")
+
+		assert.Equal(t, p.GetProperty("servers[0].host"), "a")
+		assert.Equal(t, p.GetProperty("url"), "http://b:8080")
+		assert.Equal(t, p.GetProperty("servers[2].host"), nil)
+
+		// 同一层中下标形式的属性值覆盖列表中对应的元素
+		p.SetProperty("servers[1].port", 9090)
+		p.SetProperty("servers[2].host", "c")
+
+		var c IndexedConfig
+		p.SetProperty("hosts", []string{"x"})
+		p.BindProperty("", &c)
+		assert.Equal(t, c.Servers, []IndexedServer{
+			{Host: "a", Port: 8080, Ports: []int{1}},
+			{Host: "b", Port: 9090, Ports: []int{1}},
+			{Host: "c", Port: 80, Ports: []int{1}},
+		})
+	})
+
+	t.Run("origin", func(t *testing.T) {
+		p := SpringCore.NewDefaultProperties()
+		p.SetPropertyWithOrigin("servers[0].host", "a", SpringCore.PropertyOrigin{Source: "a.properties", Line: 1})
+		p.SetPropertyWithOrigin("servers[0].port", "x", SpringCore.PropertyOrigin{Source: "a.properties", Line: 2})
+		p.SetProperty("hosts", []string{"x"})
+
+		origin, _ := p.GetPropertyOrigin("servers")
+		assert.Equal(t, origin.String(), "a.properties:1")
+
+		var c IndexedConfig
+		assert.Panic(t, func() {
+			p.BindProperty("", &c)
+		}, `property value servers\[0\].port isn't int type \(from a.properties:2\)`)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		p := SpringCore.NewDefaultProperties()
+		p.SetProperty("hosts[1]", "y")
+		assert.Panic(t, func() {
+			p.GetProperty("hosts")
+		}, `property "hosts" is missing index 0`)
+
+		p = SpringCore.NewDefaultProperties()
+		p.SetProperty("servers[0]", "a")
+		p.SetProperty("servers[0].host", "a")
+		assert.Panic(t, func() {
+			p.GetProperty("servers")
+		}, `property "servers\[0\]" has both value and sub properties`)
+
+		// 非数字的下标不是列表
+		p = SpringCore.NewDefaultProperties()
+		p.SetProperty("labels[app]", "demo")
+		assert.Equal(t, p.GetProperty("labels"), nil)
+		assert.Equal(t, p.GetProperty("labels[app]"), "demo")
+	})
+
+	t.Run("priority", func(t *testing.T) {
+		high := SpringCore.NewDefaultProperties()
+		high.SetProperty("servers[0].host", "high")
+
+		low := SpringCore.NewDefaultProperties()
+		low.SetProperty("servers[0].host", "a")
+		low.SetProperty("servers[1].host", "b")
+		low.SetProperty("hosts", []interface{}{"x", "y"})
+
+		pp := SpringCore.NewPriorityProperties(high, low)
+		assert.Equal(t, pp.GetProperty("servers"), []interface{}{
+			map[string]interface{}{"host": "high"},
+		})
+
+		// 高优先级层中的列表整体覆盖低优先级层中的列表
+		high.SetProperty("hosts[0]", "z")
+		properties := pp.GetProperties()
+		keys := make([]string, 0, len(properties))
+		for k := range properties {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		assert.Equal(t, keys, []string{"hosts[0]", "servers[0].host"})
+		assert.Equal(t, pp.GetUnusedProperties(), []string{"hosts[0]"})
+	})
+}
+
+func TestDefaultProperties_PreserveCase(t *testing.T) {
+
+	type HttpConfig struct {
+		Headers map[string]string `value:"${http.headers}" case:"preserve"`
+		Lower   map[string]string `value:"${http.headers}"`
+	}
+
+	p := SpringCore.NewDefaultProperties()
+	p.SetProperty("http.headers.X-Request-Id", "1")
+	p.SetProperty("http.headers.Content-Type", "text/plain")
+
+	var c HttpConfig
+	p.BindProperty("", &c)
+	assert.Equal(t, c.Headers, map[string]string{"X-Request-Id": "1", "Content-Type": "text/plain"})
+	assert.Equal(t, c.Lower, map[string]string{"x-request-id": "1", "content-type": "text/plain"})
+
+	assert.Equal(t, p.GetPropertyName("http.headers.x-request-id"), "http.headers.X-Request-Id")
+	assert.Equal(t, p.GetPropertyName("missing"), "missing")
+
+	// 拷贝属性值时保留属性名的大小写
+	pp := SpringCore.NewPriorityProperties(SpringCore.NewDefaultProperties(), p)
+	assert.Equal(t, pp.GetPropertyName("http.headers.content-type"), "http.headers.Content-Type")
+}
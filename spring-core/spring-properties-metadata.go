@@ -0,0 +1,221 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringCore
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// PropertyMetadata 通过 value 标签声明的属性
+type PropertyMetadata struct {
+	Name         string `json:"name"`                   // 属性名
+	Type         string `json:"type"`                   // 字段的 Go 类型
+	DefaultValue string `json:"defaultValue,omitempty"` // 默认值
+	HasDefault   bool   `json:"hasDefault,omitempty"`   // 是否有默认值
+	SourceType   string `json:"sourceType,omitempty"`   // 声明属性的结构体或者函数的类型
+	Field        string `json:"field,omitempty"`        // 声明属性的字段名，函数参数为 arg 加序号
+	Bean         string `json:"bean,omitempty"`         // 声明属性的 Bean 的 ID
+	Description  string `json:"description,omitempty"`  // 属性的描述
+}
+
+// CollectPropertyMetadata 收集 Bean 通过 value 标签声明的属性，包括构造函数和成员
+// 方法的值类型参数，结构体类型的属性展开为其字段声明的属性，返回按照属性名排序的结果。
+// 尚未决议的成员方法 Bean 被忽略，beans 中有同类型的配置属性 Bean 的无标签结构体参数
+// 使用配置属性 Bean 的值，因此也被忽略。
+func CollectPropertyMetadata(beans ...*BeanDefinition) []PropertyMetadata {
+
+	configTypes := make(map[reflect.Type]bool)
+	for _, bd := range beans {
+		if _, ok := bd.getConfigPrefix(); ok {
+			configTypes[bd.Type().Elem()] = true
+		}
+	}
+
+	var result []PropertyMetadata
+	for _, bd := range beans {
+		if _, ok := bd.bean.(*fakeMethodBean); ok {
+			continue
+		}
+
+		start := len(result)
+
+		if t := bd.Type(); t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct {
+			result = collectStructMetadata(t.Elem(), bd.configPrefix, result)
+		} else if t.Kind() == reflect.Struct {
+			result = collectStructMetadata(t, "", result)
+		}
+
+		switch b := bd.bean.(type) {
+		case *constructorBean:
+			result = collectArgMetadata(b.stringArg, configTypes, result)
+		case *methodBean:
+			result = collectArgMetadata(b.stringArg, configTypes, result)
+		}
+
+		for i := start; i < len(result); i++ {
+			result[i].Bean = bd.BeanId()
+		}
+	}
+	return MergePropertyMetadata(result)
+}
+
+// CollectStructMetadata 收集结构体通过 value 标签声明的属性，参数可以是结构体或者
+// 结构体指针，返回按照属性名排序的结果。
+func CollectStructMetadata(structs ...interface{}) []PropertyMetadata {
+	var result []PropertyMetadata
+	for _, i := range structs {
+		t := reflect.TypeOf(i)
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			panic(fmt.Errorf("%s isn't struct type", t.String()))
+		}
+		result = collectStructMetadata(t, "", result)
+	}
+	return MergePropertyMetadata(result)
+}
+
+// MergePropertyMetadata 合并多组属性，按照属性名排序，同名的属性只保留第一次出现的。
+func MergePropertyMetadata(lists ...[]PropertyMetadata) []PropertyMetadata {
+	var all []PropertyMetadata
+	for _, l := range lists {
+		all = append(all, l...)
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Name < all[j].Name
+	})
+
+	var result []PropertyMetadata
+	for i, m := range all {
+		if i == 0 || m.Name != all[i-1].Name {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// collectStructMetadata 收集结构体字段声明的属性，遍历规则和 bindStruct 一致。
+func collectStructMetadata(t reflect.Type, prefix string, result []PropertyMetadata) []PropertyMetadata {
+	for i := 0; i < t.NumField(); i++ {
+		ft := t.Field(i)
+
+		if tag, ok := ft.Tag.Lookup("value"); ok {
+			result = collectValueMetadata(ft.Type, tag, prefix, t.String(), ft.Name, result)
+			continue
+		}
+
+		if ft.Type.Kind() == reflect.Struct {
+			result = collectStructMetadata(ft.Type, prefix, result)
+		}
+	}
+	return result
+}
+
+// collectArgMetadata 收集函数的值类型参数声明的属性，遍历规则和 fnStringBindingArg 一致，
+// configTypes 是配置属性 Bean 的结构体类型。
+func collectArgMetadata(arg *fnStringBindingArg, configTypes map[reflect.Type]bool, result []PropertyMetadata) []PropertyMetadata {
+
+	fnType := arg.fnType
+	numIn := fnType.NumIn()
+	if arg.withReceiver {
+		numIn -= 1
+	}
+
+	for i, tags := range arg.fnTags {
+
+		var it reflect.Type
+		if arg.withReceiver {
+			it = fnType.In(i + 1)
+		} else {
+			it = fnType.In(i)
+		}
+
+		if fnType.IsVariadic() && i == numIn-1 {
+			it = it.Elem()
+		} else if len(tags) == 0 {
+			tags = []string{""}
+		}
+
+		if !IsValueType(it.Kind()) {
+			continue
+		}
+
+		// 没有标签的结构体参数使用同类型的配置属性 Bean 的值
+		if len(tags) == 1 && tags[0] == "" && configTypes[it] {
+			continue
+		}
+
+		for _, tag := range tags {
+			if tag == "" {
+				tag = "${}"
+			}
+			field := fmt.Sprintf("arg%d", i)
+			result = collectValueMetadata(it, tag, "", fnType.String(), field, result)
+		}
+	}
+	return result
+}
+
+// collectValueMetadata 收集 value 标签声明的属性，没有类型转换器的结构体展开为其字段声明的属性，
+// 指针类型使用它指向的类型。
+func collectValueMetadata(t reflect.Type, tag string, prefix string, sourceType string, field string, result []PropertyMetadata) []PropertyMetadata {
+
+	if !(strings.HasPrefix(tag, "${") && strings.HasSuffix(tag, "}")) {
+		return result
+	}
+
+	ss := strings.SplitN(tag[2:len(tag)-1], ":=", 2)
+
+	key := ss[0]
+	if prefix != "" {
+		if key == "" {
+			key = prefix
+		} else {
+			key = prefix + "." + key
+		}
+	}
+
+	// 指针类型的属性使用它指向的类型
+	if _, ok := defaultConverters.findType(t); !ok && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() == reflect.Struct && isCompositeType(t, nil) {
+		return collectStructMetadata(t, key, result)
+	}
+
+	if key == "" {
+		return result
+	}
+
+	m := PropertyMetadata{
+		Name:       key,
+		Type:       t.String(),
+		SourceType: sourceType,
+		Field:      field,
+	}
+	if len(ss) > 1 {
+		m.DefaultValue = ss[1]
+		m.HasDefault = true
+	}
+	return append(result, m)
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringCore_test
+
+import (
+	"testing"
+
+	"github.com/go-spring/go-spring/spring-core"
+	"github.com/magiconair/properties/assert"
+)
+
+type MetadataDB struct {
+	Url  string `value:"${url}"`
+	Pool int    `value:"${pool:=10}"`
+}
+
+type MetadataEmbedded struct {
+	Debug bool `value:"${debug:=false}"`
+}
+
+type MetadataBean struct {
+	MetadataEmbedded
+	DB      MetadataDB `value:"${db}"`
+	Timeout string     `value:"${timeout:=${default.timeout:=5s}}"`
+	Ignored string
+}
+
+type MetadataServer struct {
+	Name string
+}
+
+func NewMetadataServer(db MetadataDB, name string, port int) *MetadataServer {
+	return &MetadataServer{Name: name}
+}
+
+func TestCollectPropertyMetadata(t *testing.T) {
+
+	t.Run("struct", func(t *testing.T) {
+		bd := SpringCore.ToBeanDefinition("", new(MetadataBean))
+		other := SpringCore.ToBeanDefinition("", &MetadataDB{})
+
+		id := bd.BeanId()
+		otherId := other.BeanId()
+
+		result := SpringCore.CollectPropertyMetadata(bd, other)
+		assert.Equal(t, result, []SpringCore.PropertyMetadata{
+			{Name: "db.pool", Type: "int", DefaultValue: "10", HasDefault: true, SourceType: "SpringCore_test.MetadataDB", Field: "Pool", Bean: id},
+			{Name: "db.url", Type: "string", SourceType: "SpringCore_test.MetadataDB", Field: "Url", Bean: id},
+			{Name: "debug", Type: "bool", DefaultValue: "false", HasDefault: true, SourceType: "SpringCore_test.MetadataEmbedded", Field: "Debug", Bean: id},
+			{Name: "pool", Type: "int", DefaultValue: "10", HasDefault: true, SourceType: "SpringCore_test.MetadataDB", Field: "Pool", Bean: otherId},
+			{Name: "timeout", Type: "string", DefaultValue: "${default.timeout:=5s}", HasDefault: true, SourceType: "SpringCore_test.MetadataBean", Field: "Timeout", Bean: id},
+			{Name: "url", Type: "string", SourceType: "SpringCore_test.MetadataDB", Field: "Url", Bean: otherId},
+		})
+	})
+
+	t.Run("constructor", func(t *testing.T) {
+		bd := SpringCore.FnToBeanDefinition("", NewMetadataServer, "${server.db}", "${server.name:=go-spring}")
+
+		fnType := "func(SpringCore_test.MetadataDB, string, int) *SpringCore_test.MetadataServer"
+		result := SpringCore.CollectPropertyMetadata(bd)
+		assert.Equal(t, result, []SpringCore.PropertyMetadata{
+			{Name: "server.db.pool", Type: "int", DefaultValue: "10", HasDefault: true, SourceType: "SpringCore_test.MetadataDB", Field: "Pool", Bean: bd.BeanId()},
+			{Name: "server.db.url", Type: "string", SourceType: "SpringCore_test.MetadataDB", Field: "Url", Bean: bd.BeanId()},
+			{Name: "server.name", Type: "string", DefaultValue: "go-spring", HasDefault: true, SourceType: fnType, Field: "arg1", Bean: bd.BeanId()},
+		})
+	})
+
+	t.Run("configuration properties", func(t *testing.T) {
+		cfg := SpringCore.ConfigurationToBeanDefinition("app.db", new(MetadataDB))
+		bd := SpringCore.FnToBeanDefinition("", NewMetadataServer, "", "${server.name:=go-spring}")
+
+		// 没有标签的 MetadataDB 参数使用配置属性 Bean 的值，不再声明属性
+		fnType := "func(SpringCore_test.MetadataDB, string, int) *SpringCore_test.MetadataServer"
+		result := SpringCore.CollectPropertyMetadata(cfg, bd)
+		assert.Equal(t, result, []SpringCore.PropertyMetadata{
+			{Name: "app.db.pool", Type: "int", DefaultValue: "10", HasDefault: true, SourceType: "SpringCore_test.MetadataDB", Field: "Pool", Bean: cfg.BeanId()},
+			{Name: "app.db.url", Type: "string", SourceType: "SpringCore_test.MetadataDB", Field: "Url", Bean: cfg.BeanId()},
+			{Name: "server.name", Type: "string", DefaultValue: "go-spring", HasDefault: true, SourceType: fnType, Field: "arg1", Bean: bd.BeanId()},
+		})
+	})
+
+	t.Run("registered struct", func(t *testing.T) {
+		result := SpringCore.CollectStructMetadata(MetadataEmbedded{}, &MetadataDB{})
+		assert.Equal(t, len(result), 3)
+		assert.Equal(t, result[0].Name, "debug")
+		assert.Equal(t, result[2].Name, "url")
+
+		assert.Panic(t, func() {
+			SpringCore.CollectStructMetadata(3)
+		}, "int isn't struct type")
+	})
+}
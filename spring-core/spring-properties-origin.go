@@ -0,0 +1,69 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringCore
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PropertyOrigin 属性值的来源
+type PropertyOrigin struct {
+	Layer  string // 属性值所在的配置层，如 command-line、system-env 等
+	Source string // 属性值的出处，如文件名、config-map 的 key、环境变量的名称等
+	Line   int    // 属性值在文件中的行号，0 表示未知
+
+	// Sensitive 属性值是否敏感，比如从密钥目录读取的密码，敏感的属性值以及引用了
+	// 敏感属性值的属性值不会出现在日志和诊断输出中，见 IsSensitiveProperty。
+	Sensitive bool
+}
+
+// String 返回形如 application.properties:3 [config] 的字符串
+func (o PropertyOrigin) String() string {
+	var ss []string
+	if o.Source != "" {
+		if o.Line > 0 {
+			ss = append(ss, fmt.Sprintf("%s:%d", o.Source, o.Line))
+		} else {
+			ss = append(ss, o.Source)
+		}
+	}
+	if o.Layer != "" {
+		ss = append(ss, "["+o.Layer+"]")
+	}
+	return strings.Join(ss, " ")
+}
+
+// originSuffix 返回形如 " (from application.properties:3 [app-config])" 的
+// 来源信息，用于属性绑定的错误信息，没有来源信息时返回空字符串。
+func originSuffix(p Properties, key string) string {
+	if origin, ok := p.GetPropertyOrigin(key); ok {
+		return fmt.Sprintf(" (from %s)", origin)
+	}
+	return ""
+}
+
+// inheritOrigins 使 sub 中的属性值沿用 p 中对应属性值的来源，fn 返回 sub 中的
+// 属性名在 p 中对应的属性名。
+func inheritOrigins(sub *defaultProperties, p Properties, fn func(key string) string) *defaultProperties {
+	for k := range sub.properties {
+		if origin, ok := p.GetPropertyOrigin(fn(k)); ok {
+			sub.origins[k] = origin
+		}
+	}
+	return sub
+}
@@ -0,0 +1,181 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringCore
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// 属性值中的引用语法：
+//   ${key}          引用 key 的属性值，key 不存在时报错；
+//   ${key:=def}     引用 key 的属性值，key 不存在时使用默认值 def；
+//   ${a:=${b:=c}}   默认值也可以是引用，支持任意层的嵌套；
+//   \${key}         转义，结果为字面量 ${key}。
+// 一个属性值中可以包含多个引用，如 http://${host}:${port}/api。
+// 引用的属性值或者默认值是加密属性值时使用解密之后的明文，见 PropertyDecryptor。
+// 引用也可以是 random.int、random.uuid、hostname 等计算属性，计算结果缓存在
+// Properties 对象中，同一个对象内相同的表达式总是得到相同的值，见 computedCache。
+
+// rawProperties 能够返回未经解析的属性值的 Properties
+type rawProperties interface {
+	// getRawProperty 返回未经解析的属性值，属性名称统一转成小写。
+	getRawProperty(key string) (interface{}, bool)
+}
+
+// getRawProperty 返回未经解析的属性值
+func getRawProperty(p Properties, key string) (interface{}, bool) {
+	if r, ok := p.(rawProperties); ok {
+		return r.getRawProperty(key)
+	}
+	return p.GetDefaultProperty(key, nil)
+}
+
+// resolveProperty 解析属性值中的引用，解析失败会 panic。
+func resolveProperty(p Properties, key string, value interface{}) interface{} {
+	v, err := resolveValue(p, value, []string{strings.ToLower(key)})
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// resolveValue 解析属性值中的引用。当属性值只包含一个引用时返回被引用值的原始
+// 类型，否则返回字符串。chain 是正在解析的属性名称链，用于检测循环引用。
+func resolveValue(p Properties, value interface{}, chain []string) (interface{}, error) {
+
+	s, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+
+	// 加密的属性值解密之后不再解析引用
+	if IsEncryptedValue(s) {
+		key := ""
+		if len(chain) > 0 {
+			key = chain[len(chain)-1]
+		}
+		return decryptValue(key, s)
+	}
+
+	if !strings.Contains(s, "${") {
+		return value, nil
+	}
+
+	// 整个属性值就是一个引用时保留被引用值的类型
+	if strings.HasPrefix(s, "${") {
+		if end, err := matchPlaceholder(s, 0); err != nil {
+			return nil, err
+		} else if end == len(s)-1 {
+			return resolvePlaceholder(p, s[2:end], chain)
+		}
+	}
+
+	return resolveString(p, s, chain)
+}
+
+// resolveString 解析字符串中的所有引用，返回拼接后的字符串
+func resolveString(p Properties, s string, chain []string) (string, error) {
+	var buf strings.Builder
+
+	for i := 0; i < len(s); {
+
+		// 转义的引用原样输出
+		if strings.HasPrefix(s[i:], `\${`) {
+			buf.WriteString("${")
+			i += 3
+			continue
+		}
+
+		if !strings.HasPrefix(s[i:], "${") {
+			buf.WriteByte(s[i])
+			i++
+			continue
+		}
+
+		end, err := matchPlaceholder(s, i)
+		if err != nil {
+			return "", err
+		}
+
+		v, err := resolvePlaceholder(p, s[i+2:end], chain)
+		if err != nil {
+			return "", err
+		}
+
+		str, err := cast.ToStringE(v)
+		if err != nil {
+			return "", fmt.Errorf("property \"%s\" can't be embedded in a string: %v", s[i+2:end], err)
+		}
+
+		buf.WriteString(str)
+		i = end + 1
+	}
+
+	return buf.String(), nil
+}
+
+// matchPlaceholder 返回 start 处的引用所对应的右括号的位置
+func matchPlaceholder(s string, start int) (int, error) {
+	depth := 0
+	for i := start; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "${"):
+			depth++
+			i++
+		case s[i] == '}':
+			if depth--; depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return -1, fmt.Errorf("unclosed placeholder in \"%s\"", s)
+}
+
+// resolvePlaceholder 解析一个去掉了 ${} 的引用，形如 key 或者 key:=def。
+func resolvePlaceholder(p Properties, s string, chain []string) (interface{}, error) {
+
+	key, def, hasDef := s, "", false
+	if i := strings.Index(s, ":="); i >= 0 {
+		key, def, hasDef = s[:i], s[i+2:], true
+	}
+
+	key = strings.ToLower(strings.TrimSpace(key))
+	if key == "" {
+		return nil, errors.New("placeholder key can't be empty")
+	}
+
+	for _, k := range chain {
+		if k == key {
+			path := strings.Join(append(chain, key), " -> ")
+			return nil, fmt.Errorf("found circular property reference: %s", path)
+		}
+	}
+
+	if v, ok := lookupProperty(p, key); ok {
+		return resolveValue(p, v, append(chain[:len(chain):len(chain)], key))
+	}
+
+	if hasDef {
+		return resolveValue(p, def, chain)
+	}
+
+	return nil, fmt.Errorf("property \"%s\" not config", key)
+}
@@ -18,6 +18,9 @@ package SpringCore
 
 import (
 	"io"
+	"net/url"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/go-spring/go-spring-parent/spring-const"
@@ -26,13 +29,14 @@ import (
 
 // priorityProperties 基于优先级的 Properties 版本
 type priorityProperties struct {
-	curr Properties // 高优先级
-	next Properties // 低优先级
+	curr     Properties     // 高优先级
+	next     Properties     // 低优先级
+	computed *computedCache // 计算属性的缓存
 }
 
 // NewPriorityProperties priorityProperties 的构造函数
 func NewPriorityProperties(curr Properties, next Properties) *priorityProperties {
-	return &priorityProperties{curr: curr, next: next}
+	return &priorityProperties{curr: curr, next: next, computed: new(computedCache)}
 }
 
 // LoadProperties 加载属性配置文件，支持 properties、yaml 和 toml 三种文件格式。
@@ -45,13 +49,58 @@ func (p *priorityProperties) ReadProperties(reader io.Reader, configType string)
 	p.curr.ReadProperties(reader, configType)
 }
 
+// getRawProperty 返回未经解析的属性值，属性名称统一转成小写。
+func (p *priorityProperties) getRawProperty(key string) (interface{}, bool) {
+	if v, ok := getRawProperty(p.curr, key); ok {
+		return v, true
+	}
+	return getRawProperty(p.next, key)
+}
+
+// lookup 先按优先级再按 keys 的顺序查找第一个存在的未经解析的属性值，
+// 即高优先级层中的任一属性名都优先于低优先级层中的属性名，最后查找计算属性。
+func (p *priorityProperties) lookup(keys []string) (string, interface{}, bool) {
+	if key, v, ok := p.lookupRaw(keys); ok {
+		return key, v, true
+	}
+	// 所有层中都找不到时使用计算属性
+	for _, key := range keys {
+		if v, ok := computedProperty(p, key); ok {
+			return key, v, true
+		}
+	}
+	return "", nil, false
+}
+
+// lookupRaw 先按优先级再按 keys 的顺序查找第一个存在的未经解析的属性值。
+func (p *priorityProperties) lookupRaw(keys []string) (string, interface{}, bool) {
+	for _, key := range keys {
+		if v, ok := getRawProperty(p.curr, key); ok {
+			return key, v, true
+		}
+	}
+	if nxt, ok := p.next.(*priorityProperties); ok {
+		return nxt.lookupRaw(keys)
+	}
+	for _, key := range keys {
+		if v, ok := getRawProperty(p.next, key); ok {
+			return key, v, true
+		}
+	}
+	return "", nil, false
+}
+
+// computedCache 返回缓存计算属性的对象
+func (p *priorityProperties) computedCache() *computedCache {
+	return p.computed
+}
+
 // GetProperty 返回 keys 中第一个存在的属性值，属性名称统一转成小写。
 func (p *priorityProperties) GetProperty(keys ...string) interface{} {
-	if v := p.curr.GetProperty(keys...); v == nil {
-		return p.next.GetProperty(keys...)
-	} else {
-		return v
+	if key, v, ok := p.lookup(keys); ok {
+		return resolveProperty(p, key, v)
 	}
+	return nil
 }
 
 // GetBoolProperty 返回 keys 中第一个存在的布尔型属性值，属性名称统一转成小写。
@@ -89,36 +138,141 @@ func (p *priorityProperties) GetTimeProperty(keys ...string) time.Time {
 	return cast.ToTime(p.GetProperty(keys...))
 }
 
+// GetBoolPropertyE 返回布尔型属性值，属性值不存在时返回 ErrPropertyNotFound 错误。
+func (p *priorityProperties) GetBoolPropertyE(key string) (bool, error) {
+	return getBoolPropertyE(p, key)
+}
+
+// GetIntPropertyE 返回有符号整型属性值，属性值不存在时返回 ErrPropertyNotFound 错误。
+func (p *priorityProperties) GetIntPropertyE(key string) (int64, error) {
+	return getIntPropertyE(p, key)
+}
+
+// GetUintPropertyE 返回无符号整型属性值，属性值不存在时返回 ErrPropertyNotFound 错误。
+func (p *priorityProperties) GetUintPropertyE(key string) (uint64, error) {
+	return getUintPropertyE(p, key)
+}
+
+// GetFloatPropertyE 返回浮点型属性值，属性值不存在时返回 ErrPropertyNotFound 错误。
+func (p *priorityProperties) GetFloatPropertyE(key string) (float64, error) {
+	return getFloatPropertyE(p, key)
+}
+
+// GetStringPropertyE 返回字符串型属性值，属性值不存在时返回 ErrPropertyNotFound 错误。
+func (p *priorityProperties) GetStringPropertyE(key string) (string, error) {
+	return getStringPropertyE(p, key)
+}
+
+// GetDurationPropertyE 返回 Duration 类型的属性值，属性值不存在时返回 ErrPropertyNotFound 错误。
+func (p *priorityProperties) GetDurationPropertyE(key string) (time.Duration, error) {
+	return getDurationPropertyE(p, key)
+}
+
+// GetTimePropertyE 返回 Time 类型的属性值，属性值不存在时返回 ErrPropertyNotFound 错误。
+func (p *priorityProperties) GetTimePropertyE(key string) (time.Time, error) {
+	return getTimePropertyE(p, key)
+}
+
+// GetStringSlicePropertyE 返回字符串列表类型的属性值，字符串按照逗号切割，属性值不存在时返回 ErrPropertyNotFound 错误。
+func (p *priorityProperties) GetStringSlicePropertyE(key string) ([]string, error) {
+	return getStringSlicePropertyE(p, key)
+}
+
+// GetStringMapPropertyE 返回 map[string]string 类型的属性值，由以 key 为前缀的属性值组成，属性值不存在时返回 ErrPropertyNotFound 错误。
+func (p *priorityProperties) GetStringMapPropertyE(key string) (map[string]string, error) {
+	return getStringMapPropertyE(p, key)
+}
+
+// GetByteSizePropertyE 返回字节数，如 64KB、1.5MB、2GiB，使用 1024 进制，属性值不存在时返回 ErrPropertyNotFound 错误。
+func (p *priorityProperties) GetByteSizePropertyE(key string) (int64, error) {
+	return getByteSizePropertyE(p, key)
+}
+
+// GetURLPropertyE 返回 URL 类型的属性值，URL 必须包含 scheme，属性值不存在时返回 ErrPropertyNotFound 错误。
+func (p *priorityProperties) GetURLPropertyE(key string) (*url.URL, error) {
+	return getURLPropertyE(p, key)
+}
+
 // SetProperty 设置属性值，属性名称统一转成小写。
 func (p *priorityProperties) SetProperty(key string, value interface{}) {
 	p.curr.SetProperty(key, value)
 }
 
+// SetPropertyWithOrigin 设置属性值并记录属性值的来源，属性名称统一转成小写。
+func (p *priorityProperties) SetPropertyWithOrigin(key string, value interface{}, origin PropertyOrigin) {
+	p.curr.SetPropertyWithOrigin(key, value, origin)
+}
+
+// GetPropertyOrigin 返回属性值的来源，来源所在的层和属性值所在的层一致。
+func (p *priorityProperties) GetPropertyOrigin(key string) (PropertyOrigin, bool) {
+	if _, ok := getRawProperty(p.curr, key); ok {
+		return p.curr.GetPropertyOrigin(key)
+	}
+	return p.next.GetPropertyOrigin(key)
+}
+
 // GetDefaultProperty 返回属性值，如果没有找到则使用指定的默认值，属性名称统一转成小写。
 func (p *priorityProperties) GetDefaultProperty(key string, def interface{}) (interface{}, bool) {
-	if v, ok := p.curr.GetDefaultProperty(key, def); !ok {
-		return p.next.GetDefaultProperty(key, def)
-	} else {
-		return v, ok
+	if v, ok := lookupProperty(p, key); ok {
+		return resolveProperty(p, key, v), true
 	}
+	return def, false
 }
 
-// GetPrefixProperties 返回指定前缀的属性值集合，属性名称统一转成小写。
+// GetPrefixProperties 返回指定前缀的属性值集合，属性名称统一转成小写。高优先级层
+// 的属性值覆盖低优先级层的属性值，属性值中的引用在所有层中解析。
 func (p *priorityProperties) GetPrefixProperties(prefix string) map[string]interface{} {
-	panic(SpringConst.UnimplementedMethod)
+	prefix = strings.ToLower(prefix)
+	result := make(map[string]interface{})
+	for k := range p.GetProperties() {
+		if key, ok := relaxedPrefixMatch(k, prefix); ok {
+			v, _ := getRawProperty(p, k)
+			result[key] = resolveProperty(p, k, v)
+		}
+	}
+	return result
 }
 
-// GetProperties 返回所有的属性值，属性名称统一转成小写。
+// GetProperties 返回所有未经解析的属性值，属性名称统一转成小写。高优先级层定义
+// 了列表的任一元素时，低优先级层中的整个列表都被忽略。
 func (p *priorityProperties) GetProperties() map[string]interface{} {
-	properties := p.curr.GetProperties()
-	for key, val := range p.next.GetProperties() {
-		if _, ok := properties[key]; !ok {
+	curr := p.curr.GetProperties()
+	next := p.next.GetProperties()
+	shadowed := shadowedKeys(curr, next)
+
+	properties := make(map[string]interface{})
+	for key, val := range next {
+		if !shadowed[key] {
 			properties[key] = val
 		}
 	}
+	for key, val := range curr {
+		properties[key] = val
+	}
 	return properties
 }
 
+// GetPropertyName 返回设置属性值时使用的属性名，属性名所在的层和属性值所在的层一致。
+func (p *priorityProperties) GetPropertyName(key string) string {
+	if _, ok := getRawProperty(p.curr, key); ok {
+		return p.curr.GetPropertyName(key)
+	}
+	return p.next.GetPropertyName(key)
+}
+
+// GetUnusedProperties 返回从未被读取过的属性名，被高优先级层覆盖的属性名不返回。
+func (p *priorityProperties) GetUnusedProperties() []string {
+	shadowed := shadowedKeys(p.curr.GetProperties(), p.next.GetProperties())
+	result := p.curr.GetUnusedProperties()
+	for _, k := range p.next.GetUnusedProperties() {
+		if !shadowed[k] {
+			result = append(result, k)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
 // BindProperty 根据类型获取属性值，属性名称统一转成小写。
 func (p *priorityProperties) BindProperty(key string, i interface{}) {
 	panic(SpringConst.UnimplementedMethod)
@@ -94,3 +94,25 @@ func TestPriorityProperties_InsertBefore(t *testing.T) {
 
 	assert.Equal(t, l0.Depth(), 5)
 }
+
+func TestPriorityProperties_GetPrefixProperties(t *testing.T) {
+
+	low := SpringCore.NewDefaultProperties()
+	low.SetProperty("db.host", "localhost")
+	low.SetProperty("db.port", 3306)
+	low.SetProperty("db.url", "${db.host}:${db.port}")
+	low.SetProperty("cache.size", 64)
+
+	high := SpringCore.NewDefaultProperties()
+	high.SetProperty("db.host", "mysql")
+	high.SetProperty("db.user-name", "root")
+
+	p := SpringCore.NewPriorityProperties(high, low)
+	assert.Equal(t, p.GetPrefixProperties("DB"), map[string]interface{}{
+		"db.host":      "mysql",
+		"db.port":      3306,
+		"db.url":       "mysql:3306",
+		"db.user-name": "root",
+	})
+	assert.Equal(t, len(p.GetPrefixProperties("redis")), 0)
+}
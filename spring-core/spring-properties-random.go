@@ -0,0 +1,187 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringCore
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 计算属性，在所有配置层中都找不到属性名时使用，可以用于引用和 value 标签：
+//   random.int            [0, 2^31) 之间的随机整数；
+//   random.int(max)       [0, max) 之间的随机整数，也可以写成 random.int[max]；
+//   random.int(min,max)   [min, max) 之间的随机整数；
+//   random.long           [0, 2^63) 之间的随机整数，同样支持参数；
+//   random.uuid           随机的 UUID；
+//   random.value          32 位十六进制的随机字符串；
+//   hostname              主机名；
+//   pid                   进程 ID。
+//
+// 计算结果缓存在 Properties 对象中，同一个 Properties 对象内相同的表达式只计算一次，
+// 多次读取时结果保持一致；不同的 Properties 对象以及不同的 SpringContext 各自计算，
+// 互不影响。SpringContext 刷新属性值时沿用之前的计算结果，见 contextProperties。
+
+// computedCache 已经计算过的计算属性，表达式 -> 属性值
+type computedCache struct {
+	values sync.Map
+}
+
+// get 返回计算属性的值，没有计算过时计算并缓存，key 不是计算属性时返回 false。
+func (c *computedCache) get(key string) (interface{}, bool) {
+
+	if v, ok := c.values.Load(key); ok {
+		return v, true
+	}
+
+	v, ok := computeProperty(key)
+	if !ok {
+		return nil, false
+	}
+
+	v, _ = c.values.LoadOrStore(key, v)
+	return v, true
+}
+
+// computedProperties 缓存计算属性的 Properties
+type computedProperties interface {
+	// computedCache 返回缓存计算属性的对象
+	computedCache() *computedCache
+}
+
+// lookupProperty 返回未经解析的属性值，找不到时返回计算属性的值。
+func lookupProperty(p Properties, key string) (interface{}, bool) {
+	if v, ok := getRawProperty(p, key); ok {
+		return v, true
+	}
+	return computedProperty(p, key)
+}
+
+// computedProperty 返回计算属性的值，key 不是计算属性时返回 false。p 没有缓存
+// 计算属性时每次都重新计算。
+func computedProperty(p Properties, key string) (interface{}, bool) {
+	key = strings.ToLower(strings.TrimSpace(key))
+	if c, ok := p.(computedProperties); ok {
+		return c.computedCache().get(key)
+	}
+	return computeProperty(key)
+}
+
+// computeProperty 计算属性的值，表达式非法时 panic。
+func computeProperty(key string) (interface{}, bool) {
+	switch key {
+	case "hostname":
+		hostname, err := os.Hostname()
+		if err != nil {
+			panic(err)
+		}
+		return hostname, true
+	case "pid":
+		return os.Getpid(), true
+	case "random.uuid":
+		b := randomBytes(16)
+		b[6] = (b[6] & 0x0f) | 0x40 // version 4
+		b[8] = (b[8] & 0x3f) | 0x80 // variant RFC 4122
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), true
+	case "random.value":
+		return hex.EncodeToString(randomBytes(16)), true
+	}
+
+	if args, ok := randomArgs(key, "random.int"); ok {
+		return randomRange(key, args, math.MaxInt32), true
+	}
+
+	if args, ok := randomArgs(key, "random.long"); ok {
+		return randomRange(key, args, math.MaxInt64), true
+	}
+
+	return nil, false
+}
+
+// randomArgs 返回 key 中 name 之后的参数部分，key 只能是 name 本身或者 name 之后紧跟
+// ( 或者 [，比如 random.integer 不是计算属性。
+func randomArgs(key string, name string) (string, bool) {
+	if !strings.HasPrefix(key, name) {
+		return "", false
+	}
+	args := key[len(name):]
+	if args != "" && args[0] != '(' && args[0] != '[' {
+		return "", false
+	}
+	return args, true
+}
+
+// randomRange 解析形如 (min,max)、[max] 的参数，返回 [min, max) 之间的随机整数，
+// 没有参数时返回 [0, limit) 之间的随机整数。
+func randomRange(key string, args string, limit int64) int64 {
+
+	min, max := int64(0), limit
+
+	if args != "" {
+		if len(args) < 3 || !(args[0] == '(' && args[len(args)-1] == ')' ||
+			args[0] == '[' && args[len(args)-1] == ']') {
+			panic(fmt.Errorf("invalid random property \"%s\"", key))
+		}
+
+		ss := strings.Split(args[1:len(args)-1], ",")
+		if len(ss) > 2 {
+			panic(fmt.Errorf("invalid random property \"%s\"", key))
+		}
+
+		var nums []int64
+		for _, s := range ss {
+			n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+			if err != nil {
+				panic(fmt.Errorf("invalid random property \"%s\"", key))
+			}
+			nums = append(nums, n)
+		}
+
+		if len(nums) == 1 {
+			max = nums[0]
+		} else {
+			min, max = nums[0], nums[1]
+		}
+	}
+
+	if min >= max {
+		panic(fmt.Errorf("invalid random property \"%s\": min must be less than max", key))
+	}
+
+	// 使用 big.Int 计算范围，防止 max-min 溢出
+	r := new(big.Int).Sub(big.NewInt(max), big.NewInt(min))
+	n, err := rand.Int(rand.Reader, r)
+	if err != nil {
+		panic(err)
+	}
+	return n.Add(n, big.NewInt(min)).Int64()
+}
+
+// randomBytes 返回 n 个随机字节
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringCore_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/go-spring/go-spring/spring-core"
+	"github.com/magiconair/properties/assert"
+)
+
+func TestComputedProperties(t *testing.T) {
+
+	p := SpringCore.NewDefaultProperties()
+	p.SetProperty("instance.id", "app-${random.uuid}")
+	p.SetProperty("server.port", "${random.int(20000,30000)}")
+	p.SetProperty("consumer.group", "${hostname}-${pid}")
+
+	t.Run("random", func(t *testing.T) {
+		n := p.GetIntProperty("random.int(10,100)")
+		assert.Equal(t, n >= 10 && n < 100, true)
+
+		assert.Equal(t, p.GetIntProperty("random.int[5]") < 5, true)
+		assert.Equal(t, p.GetIntProperty("random.long") >= 0, true)
+		assert.Matches(t, p.GetStringProperty("random.value"), "^[0-9a-f]{32}$")
+		assert.Matches(t, p.GetStringProperty("instance.id"),
+			"^app-[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$")
+
+		// 同一个 Properties 内相同的表达式只计算一次
+		assert.Equal(t, p.GetProperty("instance.id"), p.GetProperty("instance.id"))
+		assert.Equal(t, p.GetIntProperty("random.int(10,100)"), n)
+	})
+
+	t.Run("independent", func(t *testing.T) {
+		p1 := SpringCore.NewDefaultProperties()
+		p2 := SpringCore.NewDefaultProperties()
+		assert.Equal(t, p1.GetProperty("random.uuid") == p2.GetProperty("random.uuid"), false)
+		assert.Equal(t, p1.GetProperty("random.value") == p2.GetProperty("random.value"), false)
+
+		ctx1 := SpringCore.NewDefaultSpringContext()
+		ctx2 := SpringCore.NewDefaultSpringContext()
+		assert.Equal(t, ctx1.GetProperty("random.uuid") == ctx2.GetProperty("random.uuid"), false)
+	})
+
+	t.Run("refresh", func(t *testing.T) {
+		ctx := SpringCore.NewDefaultSpringContext()
+		ctx.SetProperty("instance.id", "app-${random.uuid}")
+		id := ctx.GetStringProperty("instance.id")
+
+		// 刷新属性值之后计算属性的值保持不变
+		np := SpringCore.NewDefaultProperties()
+		np.SetProperty("instance.id", "app-${random.uuid}")
+		np.SetProperty("server.port", 8080)
+		_, err := ctx.RefreshProperties(np)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, ctx.GetStringProperty("instance.id"), id)
+	})
+
+	t.Run("host", func(t *testing.T) {
+		hostname, _ := os.Hostname()
+		assert.Equal(t, p.GetProperty("hostname"), hostname)
+		assert.Equal(t, p.GetProperty("pid"), os.Getpid())
+		assert.Equal(t, p.GetIntProperty("PID"), int64(os.Getpid()))
+	})
+
+	t.Run("bind", func(t *testing.T) {
+		type ServerConfig struct {
+			Port     int    `value:"${server.port}"`
+			TestPort int    `value:"${random.int(8000,9000)}"`
+			Group    string `value:"${consumer.group}"`
+		}
+
+		var c ServerConfig
+		p.BindProperty("", &c)
+		assert.Equal(t, c.Port >= 20000 && c.Port < 30000, true)
+		assert.Equal(t, c.TestPort >= 8000 && c.TestPort < 9000, true)
+		assert.Matches(t, c.Group, "-[0-9]+$")
+	})
+
+	t.Run("priority", func(t *testing.T) {
+		low := SpringCore.NewDefaultProperties()
+		low.SetProperty("hostname", "configured")
+		pp := SpringCore.NewPriorityProperties(SpringCore.NewDefaultProperties(), low)
+
+		// 配置的属性值优先于计算属性
+		assert.Equal(t, pp.GetProperty("hostname"), "configured")
+		v, ok := pp.GetDefaultProperty("random.int(1,2)", nil)
+		assert.Equal(t, ok, true)
+		assert.Equal(t, v, int64(1))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, key := range []string{"random.int(a)", "random.int(1,2,3)", "random.int(1", "random.int(1]"} {
+			assert.Panic(t, func() {
+				p.GetProperty(key)
+			}, "invalid random property")
+		}
+
+		assert.Panic(t, func() {
+			p.GetProperty("random.int(5,5)")
+		}, `invalid random property "random.int\(5,5\)": min must be less than max`)
+
+		assert.Equal(t, p.GetProperty("random"), nil)
+	})
+
+	t.Run("not computed", func(t *testing.T) {
+		assert.Equal(t, p.GetProperty("random.integer"), nil)
+		assert.Equal(t, p.GetProperty("random.longest"), nil)
+
+		p.SetProperty("retry.count", "${random.integer:=5}")
+		assert.Equal(t, p.GetIntProperty("retry.count"), int64(5))
+	})
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringCore
+
+import (
+	"strings"
+	"unicode"
+)
+
+// 属性名称的宽松匹配规则：
+//
+// 规范形式：全部小写，使用 . 分隔层级，同一层级内的单词使用 - 连接，
+// 例如 web.server.max-idle-conns。命令行参数的属性名会被转换成规范形式。
+//
+// 宽松形式：maxIdleConns、max-idle-conns、max_idle_conns 以及
+// MAX_IDLE_CONNS 等写法在查找属性值时都被视为同一个属性名，即比较时
+// 忽略大小写以及同一层级内的 - 和 _。
+//
+// 环境变量形式：由于环境变量的名称中不能使用 . 和 -，所以环境变量中的
+// _ 被当作层级分隔符，例如 WEB_SERVER_PORT 对应 web.server.port，
+// WEB_SERVER_MAXIDLECONNS 对应 web.server.max-idle-conns。
+
+// CanonicalPropertyKey 返回属性名的规范形式，如 Web.Server.maxIdle_Conns
+// 返回 web.server.max-idle-conns。
+func CanonicalPropertyKey(key string) string {
+	var buf strings.Builder
+	runes := []rune(key)
+	for i, r := range runes {
+		switch {
+		case r == '_':
+			buf.WriteRune('-')
+		case unicode.IsUpper(r):
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])) {
+				buf.WriteRune('-')
+			}
+			buf.WriteRune(unicode.ToLower(r))
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	return buf.String()
+}
+
+// EnvToPropertyKey 将环境变量的名称转换为属性名，如 WEB_SERVER_PORT 返回
+// web.server.port。已经包含 . 的环境变量名称只做小写转换。
+func EnvToPropertyKey(env string) string {
+	key := strings.ToLower(env)
+	if strings.Contains(key, ".") {
+		return key
+	}
+	return strings.Replace(key, "_", ".", -1)
+}
+
+// uniformPropertyKey 返回属性名的宽松形式，用于属性名的宽松比较。
+func uniformPropertyKey(key string) string {
+	key = strings.ToLower(key)
+	key = strings.Replace(key, "-", "", -1)
+	return strings.Replace(key, "_", "", -1)
+}
+
+// relaxedPrefixMatch 判断属性名 key 是否宽松匹配前缀 prefix，如果匹配则返回以
+// prefix 开头的属性名，这样调用方就可以使用 prefix 安全地裁剪属性名。
+func relaxedPrefixMatch(key string, prefix string) (string, bool) {
+	if key == prefix {
+		return key, true
+	}
+	if strings.HasPrefix(key, prefix+".") {
+		return key, true
+	}
+
+	keySegments := strings.Split(key, ".")
+	prefixSegments := strings.Split(prefix, ".")
+	if len(keySegments) < len(prefixSegments) {
+		return "", false
+	}
+
+	for i, s := range prefixSegments {
+		if uniformPropertyKey(s) != uniformPropertyKey(keySegments[i]) {
+			return "", false
+		}
+	}
+
+	if rest := keySegments[len(prefixSegments):]; len(rest) > 0 {
+		return prefix + "." + strings.Join(rest, "."), true
+	}
+	return prefix, true
+}
@@ -0,0 +1,431 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringCore
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-spring/go-spring-parent/spring-utils"
+)
+
+// 属性值的校验：属性绑定之后按照字段的 validate 标签校验字段的值，多个规则使用逗号
+// 分隔，如：
+//   Port  int    `value:"${port}" validate:"min=1,max=65535"`
+//   Mode  string `value:"${mode:=}" validate:"required,oneof=debug release"`
+//   Name  string `value:"${name}" validate:"regexp=^[a-z][a-z0-9-]*$"`
+// 支持的规则：required 要求值不是零值；min 和 max 对于数值比较大小，对于字符串、列表
+// 和 map 比较长度，time.Duration 类型可以使用 1s 形式的参数；oneof 要求值是空白分隔
+// 的候选值之一；regexp 要求值匹配正则表达式，正则表达式中可以包含逗号，因此 regexp
+// 只能是最后一个规则。指针类型的字段为 nil 时只校验 required 规则。
+//
+// 结构体绑定之后如果实现了 Validator 接口还会调用它的 Validate 方法，用于校验多个
+// 字段之间的关系，比如启用 https 时必须配置证书。同一次绑定中的所有校验错误汇总为一个
+// ValidationError。AutoWireBeans 在执行 Config 函数之后、创建和注入 Bean 之前绑定并
+// 校验所有对象 Bean 的属性值以及构造函数和成员方法的结构体参数，此时属性值绑定失败的
+// 错误也汇总到其中。Config 函数的参数依赖的 Bean 在执行 Config 函数时完成注入，这些
+// Bean 的属性值在注入时绑定和校验。
+
+// Validator 绑定属性值之后需要校验的结构体，Validate 方法只应该校验绑定的属性值，
+// 返回 *PropertyError 时可以指定出错的属性名。
+type Validator interface {
+	Validate() error
+}
+
+// ValidationError 属性值校验失败的错误，包含所有校验失败的属性。
+type ValidationError struct {
+	Errors []*PropertyError
+}
+
+// Error 返回每行一个校验错误的错误信息
+func (e *ValidationError) Error() string {
+	s := "property validation failed:"
+	for _, err := range e.Errors {
+		s += "\n    " + err.Error()
+	}
+	return s
+}
+
+// validation 收集一次属性绑定中的校验错误
+type validation struct {
+	errors     []*PropertyError
+	bindErrors bool // 是否同时收集属性值绑定失败的错误，否则绑定失败时立即 panic
+}
+
+// add 添加一个校验错误
+func (v *validation) add(err *PropertyError) {
+	v.errors = append(v.errors, err)
+}
+
+// addBindError 添加 key 的属性值绑定失败的错误，r 是绑定时 panic 的值。绑定错误的
+// 信息中已经包含了属性值的来源。
+func (v *validation) addBindError(key string, r interface{}) {
+	v.add(&PropertyError{Key: key, Err: fmt.Errorf("can't be bound: %v", r)})
+}
+
+// err 返回按照属性名排序的校验错误，没有校验错误时返回 nil。
+func (v *validation) err() error {
+	if len(v.errors) == 0 {
+		return nil
+	}
+	sort.SliceStable(v.errors, func(i, j int) bool {
+		return v.errors[i].Key < v.errors[j].Key
+	})
+	return &ValidationError{Errors: v.errors}
+}
+
+// reportViolation 报告属性值的校验错误，没有收集校验错误时直接 panic。
+func reportViolation(p Properties, key string, err error, opt bindOption) {
+
+	e, ok := err.(*PropertyError)
+	if !ok {
+		e = &PropertyError{Key: key, Err: err}
+		if origin, ok := p.GetPropertyOrigin(key); ok && key != "" {
+			e.Origin = &origin
+		}
+	}
+
+	if opt.validation != nil {
+		opt.validation.add(e)
+	} else {
+		panic(&ValidationError{Errors: []*PropertyError{e}})
+	}
+}
+
+// validateRule 校验规则，如 min=1
+type validateRule struct {
+	name string
+	arg  string
+}
+
+// parseValidateRules 解析 validate 标签，regexp 规则包含剩余的全部内容。
+func parseValidateRules(tag string) ([]validateRule, error) {
+	var rules []validateRule
+	for s := tag; s != ""; {
+		var r string
+		if strings.HasPrefix(s, "regexp=") {
+			r, s = s, ""
+		} else if i := strings.Index(s, ","); i >= 0 {
+			r, s = s[:i], s[i+1:]
+		} else {
+			r, s = s, ""
+		}
+
+		rule := validateRule{name: strings.TrimSpace(r)}
+		if i := strings.Index(rule.name, "="); i >= 0 {
+			rule.name, rule.arg = rule.name[:i], rule.name[i+1:]
+		}
+
+		switch rule.name {
+		case "required":
+		case "min", "max", "oneof", "regexp":
+			if rule.arg == "" {
+				return nil, fmt.Errorf("%s needs an argument", rule.name)
+			}
+		default:
+			return nil, fmt.Errorf("unknown rule \"%s\"", rule.name)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// validateValue 按照 validate 标签校验字段的值
+func validateValue(p Properties, v reflect.Value, key string, opt bindOption) {
+
+	rules, err := parseValidateRules(opt.validate)
+	if err != nil {
+		panic(fmt.Errorf("%s validate tag \"%s\" is invalid: %v", opt.fieldName, opt.validate, err))
+	}
+
+	// 私有字段需要开放之后才能读取
+	if v = SpringUtils.ValuePatchIf(v, opt.allAccess); !v.CanInterface() {
+		return
+	}
+
+	for _, rule := range rules {
+		if rule.name == "required" {
+			if isEmptyValue(v) {
+				reportViolation(p, key, errors.New("is required"), opt)
+			}
+			continue
+		}
+
+		ev := v
+		if ev.Kind() == reflect.Ptr {
+			if ev.IsNil() {
+				continue
+			}
+			ev = ev.Elem()
+		}
+
+		if err := checkRule(ev, rule); err != nil {
+			if _, ok := err.(*invalidRuleError); ok {
+				panic(fmt.Errorf("%s validate tag \"%s\" is invalid: %v", opt.fieldName, opt.validate, err))
+			}
+			reportViolation(p, key, err, opt)
+		}
+	}
+}
+
+// invalidRuleError 校验规则不适用于字段的类型或者参数错误
+type invalidRuleError struct {
+	msg string
+}
+
+// Error 返回错误信息
+func (e *invalidRuleError) Error() string {
+	return e.msg
+}
+
+// durationType time.Duration 的类型
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// checkRule 使用单个规则校验值，校验失败时返回校验错误，规则错误时返回 invalidRuleError。
+func checkRule(v reflect.Value, rule validateRule) error {
+	switch rule.name {
+	case "min", "max":
+		return checkRange(v, rule)
+	case "oneof":
+		s := fmt.Sprint(v.Interface())
+		options := strings.Fields(rule.arg)
+		for _, o := range options {
+			if s == o {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of [%s], got %s", strings.Join(options, " "), s)
+	case "regexp":
+		r, err := regexp.Compile(rule.arg)
+		if err != nil {
+			return &invalidRuleError{err.Error()}
+		}
+		if s := fmt.Sprint(v.Interface()); !r.MatchString(s) {
+			return fmt.Errorf("must match %s, got %s", rule.arg, s)
+		}
+	}
+	return nil
+}
+
+// checkRange 校验 min 和 max 规则，字符串、列表和 map 比较长度。
+func checkRange(v reflect.Value, rule validateRule) error {
+
+	var (
+		n     float64
+		limit float64
+		what  = "be"
+		err   error
+	)
+
+	switch k := v.Kind(); {
+	case v.Type() == durationType:
+		var d time.Duration
+		if d, err = time.ParseDuration(rule.arg); err != nil {
+			return &invalidRuleError{err.Error()}
+		}
+		if (rule.name == "min" && v.Int() < int64(d)) || (rule.name == "max" && v.Int() > int64(d)) {
+			return rangeError(rule, "be", time.Duration(v.Int()))
+		}
+		return nil
+	case k >= reflect.Int && k <= reflect.Int64:
+		n = float64(v.Int())
+	case k >= reflect.Uint && k <= reflect.Uint64:
+		n = float64(v.Uint())
+	case k == reflect.Float32 || k == reflect.Float64:
+		n = v.Float()
+	case k == reflect.String || k == reflect.Slice || k == reflect.Map || k == reflect.Array:
+		n, what = float64(v.Len()), "have length"
+	default:
+		return &invalidRuleError{fmt.Sprintf("%s doesn't support %s", rule.name, v.Type())}
+	}
+
+	if limit, err = strconv.ParseFloat(rule.arg, 64); err != nil {
+		return &invalidRuleError{fmt.Sprintf("%s isn't a number", rule.arg)}
+	}
+
+	if (rule.name == "min" && n < limit) || (rule.name == "max" && n > limit) {
+		if what == "be" {
+			return rangeError(rule, what, v.Interface())
+		}
+		return rangeError(rule, what, v.Len())
+	}
+	return nil
+}
+
+// rangeError 返回形如 must be <= 65535, got 70000 的校验错误
+func rangeError(rule validateRule, what string, got interface{}) error {
+	op := ">="
+	if rule.name == "max" {
+		op = "<="
+	}
+	return fmt.Errorf("must %s %s %s, got %v", what, op, rule.arg, got)
+}
+
+// isEmptyValue 返回是否是零值，列表和 map 长度为 0 时也是零值。
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	case reflect.Slice, reflect.Map, reflect.String, reflect.Array:
+		return v.Len() == 0
+	}
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
+
+// validateStruct 调用结构体的 Validate 方法，key 为空时使用结构体的名称。
+func validateStruct(p Properties, v reflect.Value, key string, opt bindOption) {
+
+	// 私有字段需要开放之后才能读取
+	if v = SpringUtils.ValuePatchIf(v, opt.allAccess); !v.CanAddr() || !v.CanInterface() {
+		return
+	}
+
+	validator, ok := v.Addr().Interface().(Validator)
+	if !ok {
+		return
+	}
+
+	if err := validator.Validate(); err != nil {
+		if key == "" {
+			key = opt.fieldName
+		}
+		reportViolation(p, key, err, opt)
+	}
+}
+
+// hasValueField 返回结构体是否有 value 标签的字段，遍历规则和 bindStruct 一致。
+func hasValueField(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		ft := t.Field(i)
+		if _, ok := ft.Tag.Lookup("value"); ok {
+			return true
+		}
+		if ft.Type.Kind() == reflect.Struct && hasValueField(ft.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateBeans 在创建和注入 Bean 之前绑定对象 Bean 的属性值以及构造函数和成员方法
+// 的结构体参数，并且进行校验，汇总所有 Bean 的绑定错误和校验错误。绑定的结果在注入
+// 时直接使用，因此每个属性值只绑定一次，Validate 方法也只调用一次。已经在执行 Config
+// 函数时完成注入的 Bean 不再处理。
+func (ctx *defaultSpringContext) validateBeans() {
+
+	val := &validation{bindErrors: true}
+	for _, bd := range ctx.beanMap {
+		if bd.status == beanStatus_Deleted || bd.status == beanStatus_Wired {
+			continue
+		}
+		switch bean := bd.springBean().(type) {
+		case *objectBean:
+			if t := bd.Type(); t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct {
+				ctx.bindObjectBean(bd, val)
+			}
+		case *constructorBean:
+			ctx.bindStructArgs(bean.stringArg, val)
+		case *methodBean:
+			ctx.bindStructArgs(bean.stringArg, val)
+		}
+	}
+
+	if err := val.err(); err != nil {
+		panic(err)
+	}
+}
+
+// bindObjectBean 绑定对象 Bean 的属性值并且进行校验，配置属性 Bean 使用属性名前缀
+// 绑定所有的字段，注入时不再绑定 Bean 的属性值。
+func (ctx *defaultSpringContext) bindObjectBean(bd *BeanDefinition, val *validation) {
+
+	ev := bd.Value().Elem()
+	et := ev.Type()
+	if !hasValueField(et) {
+		return
+	}
+
+	var etName string // 可能是内置类型
+	if etName = et.Name(); etName == "" {
+		etName = et.String()
+	}
+
+	opt := bindOption{
+		propNamePrefix: bd.configPrefix,
+		fullPropName:   bd.configPrefix,
+		allAccess:      ctx.AllAccess(),
+		fieldName:      etName,
+		converters:     ctx.converters,
+		validation:     val,
+	}
+
+	bindStruct(ctx, ev, opt)
+	validateStruct(ctx, ev, bd.configPrefix, opt)
+	bd.valueBound = true
+}
+
+// bindStructArgs 绑定函数 Bean 的结构体参数并且进行校验，绑定的参数值保存在 arg 中，
+// 创建 Bean 时直接使用。没有标签并且存在同类型配置属性 Bean 的参数使用配置属性 Bean
+// 的值，可变参数在创建 Bean 时绑定。
+func (ctx *defaultSpringContext) bindStructArgs(arg *fnStringBindingArg, val *validation) {
+
+	if arg == nil {
+		return
+	}
+
+	for i, tags := range arg.fnTags {
+		if arg.fnType.IsVariadic() && i == len(arg.fnTags)-1 {
+			continue
+		}
+
+		it := arg.argType(i)
+		if it.Kind() != reflect.Struct || !hasValueField(it) {
+			continue
+		}
+
+		var tag string
+		if len(tags) > 0 {
+			tag = tags[0]
+		}
+
+		if tag == "" {
+			if ctx.findConfigurationBean(it) != nil {
+				continue
+			}
+			tag = "${}"
+		}
+
+		v := reflect.New(it).Elem()
+		bindStructField(ctx, v, tag, bindOption{
+			allAccess:  ctx.AllAccess(),
+			fieldName:  it.String(),
+			converters: ctx.converters,
+			validation: val,
+		})
+
+		if arg.bound == nil {
+			arg.bound = make(map[int]reflect.Value)
+		}
+		arg.bound[i] = v
+	}
+}
@@ -0,0 +1,323 @@
+/*
+ * Copyright 2012-2019 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package SpringCore_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-spring/go-spring/spring-core"
+	"github.com/magiconair/properties/assert"
+)
+
+type WebServerConfig struct {
+	Port        int           `value:"${port}" validate:"min=1,max=65535"`
+	Mode        string        `value:"${mode:=release}" validate:"oneof=debug release"`
+	Name        string        `value:"${name:=web}" validate:"required,regexp=^[a-z]{1,8}$"`
+	Timeout     time.Duration `value:"${timeout:=1s}" validate:"max=1m"`
+	Hosts       []string      `value:"${hosts:=localhost}" validate:"min=1"`
+	Backlog     *int          `value:"${backlog}" validate:"min=16"`
+	EnableHTTPS bool          `value:"${enable-https:=false}"`
+	SSLCert     string        `value:"${ssl-cert:=}"`
+}
+
+func (c *WebServerConfig) Validate() error {
+	if c.EnableHTTPS && c.SSLCert == "" {
+		return errors.New("ssl-cert is required when enable-https is true")
+	}
+	return nil
+}
+
+func validationErrors(fn func()) (result []string) {
+	defer func() {
+		if e, ok := recover().(*SpringCore.ValidationError); ok {
+			for _, err := range e.Errors {
+				result = append(result, err.Error())
+			}
+		}
+	}()
+	fn()
+	return nil
+}
+
+func TestBindProperty_Validate(t *testing.T) {
+
+	t.Run("success", func(t *testing.T) {
+		p := SpringCore.NewDefaultProperties()
+		p.SetProperty("server.port", 8080)
+		p.SetProperty("server.backlog", 128)
+
+		var c WebServerConfig
+		p.BindProperty("server", &c)
+		assert.Equal(t, c.Port, 8080)
+		assert.Equal(t, *c.Backlog, 128)
+	})
+
+	t.Run("aggregate", func(t *testing.T) {
+		origin := SpringCore.PropertyOrigin{Source: "application.properties"}
+
+		p := SpringCore.NewDefaultProperties()
+		origin.Line = 1
+		p.SetPropertyWithOrigin("server.port", 70000, origin)
+		origin.Line = 2
+		p.SetPropertyWithOrigin("server.mode", "test", origin)
+		p.SetProperty("server.name", "Web,Server")
+		p.SetProperty("server.timeout", "2m")
+		p.SetProperty("server.hosts", []string{})
+		p.SetProperty("server.backlog", 8)
+		p.SetProperty("server.enable-https", true)
+
+		var c WebServerConfig
+		errs := validationErrors(func() { p.BindProperty("server", &c) })
+		assert.Equal(t, errs, []string{
+			`property "server" ssl-cert is required when enable-https is true`,
+			`property "server.backlog" must be >= 16, got 8`,
+			`property "server.hosts" must have length >= 1, got 0`,
+			`property "server.mode" must be one of [debug release], got test (from application.properties:2)`,
+			`property "server.name" must match ^[a-z]{1,8}$, got Web,Server`,
+			`property "server.port" must be <= 65535, got 70000 (from application.properties:1)`,
+			`property "server.timeout" must be <= 1m, got 2m0s`,
+		})
+	})
+
+	t.Run("required", func(t *testing.T) {
+		p := SpringCore.NewDefaultProperties()
+		p.SetProperty("server.port", 80)
+		p.SetProperty("server.name", "")
+
+		var c WebServerConfig
+		errs := validationErrors(func() { p.BindProperty("server", &c) })
+		assert.Equal(t, errs, []string{
+			`property "server.name" is required`,
+			`property "server.name" must match ^[a-z]{1,8}$, got `,
+		})
+	})
+
+	t.Run("property error", func(t *testing.T) {
+		p := SpringCore.NewDefaultProperties()
+		p.SetProperty("a", 1)
+
+		var c struct {
+			Nested keyedValidator `value:"${}"`
+		}
+		errs := validationErrors(func() { p.BindProperty("", &c) })
+		assert.Equal(t, errs, []string{`property "b" must be set with a`})
+	})
+
+	t.Run("invalid tag", func(t *testing.T) {
+		p := SpringCore.NewDefaultProperties()
+		p.SetProperty("port", 80)
+
+		var c struct {
+			Port int `value:"${port}" validate:"min=a"`
+		}
+		assert.Panic(t, func() {
+			p.BindProperty("", &c)
+		}, `validate tag "min=a" is invalid: a isn't a number`)
+
+		var d struct {
+			Port int `value:"${port}" validate:"between=1"`
+		}
+		assert.Panic(t, func() {
+			p.BindProperty("", &d)
+		}, `validate tag "between=1" is invalid: unknown rule "between"`)
+	})
+}
+
+type keyedValidator struct {
+	A int `value:"${a}"`
+	B int `value:"${b:=0}"`
+}
+
+func (v *keyedValidator) Validate() error {
+	if v.A != 0 && v.B == 0 {
+		return &SpringCore.PropertyError{Key: "b", Err: errors.New("must be set with a")}
+	}
+	return nil
+}
+
+type validatedService struct {
+	Port int `value:"${service.port}" validate:"max=1024"`
+}
+
+type validatedFactory struct{}
+
+func (f *validatedFactory) NewServer(c WebServerConfig) *validatedServer {
+	return &validatedServer{port: c.Port}
+}
+
+type validatedServer struct {
+	port int
+}
+
+type countedConfig struct {
+	Port int `value:"${port}"`
+}
+
+var countedValidations int
+
+func (c *countedConfig) Validate() error {
+	countedValidations++
+	return nil
+}
+
+func TestDefaultSpringContext_Validate(t *testing.T) {
+
+	t.Run("autowire", func(t *testing.T) {
+		ctx := SpringCore.NewDefaultSpringContext()
+		ctx.SetProperty("server.port", 0)
+		ctx.SetProperty("server.enable-https", true)
+		ctx.SetProperty("service.port", 8080)
+
+		constructed := false
+		ctx.RegisterBeanFn(func() *int {
+			constructed = true
+			return new(int)
+		})
+
+		ctx.RegisterBean(&struct {
+			Server WebServerConfig `value:"${server}"`
+		}{})
+		ctx.RegisterBean(&validatedService{})
+
+		// 条件不满足的 Bean 不校验
+		ctx.RegisterNameBean("disabled", &validatedService{}).ConditionOnProperty("disabled")
+
+		errs := validationErrors(func() { ctx.AutoWireBeans() })
+		assert.Equal(t, errs, []string{
+			`property "server" ssl-cert is required when enable-https is true`,
+			`property "server.port" must be >= 1, got 0`,
+			`property "service.port" must be <= 1024, got 8080`,
+		})
+		assert.Equal(t, constructed, false)
+	})
+
+	t.Run("function args", func(t *testing.T) {
+		ctx := SpringCore.NewDefaultSpringContext()
+		ctx.SetProperty("server.port", 70000)
+		ctx.SetProperty("admin.mode", "test")
+		ctx.SetProperty("admin.port", 8081)
+
+		constructed := false
+		ctx.RegisterBeanFn(func(c WebServerConfig) *int {
+			constructed = true
+			return new(int)
+		}, "${server}")
+
+		ctx.RegisterBean(&validatedFactory{})
+		ctx.RegisterMethodBean((*validatedFactory)(nil), "NewServer", "${admin}")
+
+		errs := validationErrors(func() { ctx.AutoWireBeans() })
+		assert.Equal(t, errs, []string{
+			`property "admin.mode" must be one of [debug release], got test`,
+			`property "server.port" must be <= 65535, got 70000`,
+		})
+		assert.Equal(t, constructed, false)
+	})
+
+	t.Run("bind errors", func(t *testing.T) {
+		ctx := SpringCore.NewDefaultSpringContext()
+		ctx.SetProperty("server.port", "abc")
+		ctx.SetProperty("server.mode", "test")
+		ctx.SetProperty("service.port", 8080)
+
+		ctx.RegisterBeanFn(func(c WebServerConfig) *int { return new(int) }, "${server}")
+		ctx.RegisterBean(&validatedService{})
+
+		errs := validationErrors(func() { ctx.AutoWireBeans() })
+		assert.Equal(t, len(errs), 3)
+		assert.Matches(t, errs[0], `^property "server.mode" must be one of \[debug release\], got test$`)
+		assert.Matches(t, errs[1], `^property "server.port" can't be bound: .*isn't int type`)
+		assert.Matches(t, errs[2], `^property "service.port" must be <= 1024, got 8080$`)
+	})
+
+	t.Run("bind once", func(t *testing.T) {
+		ctx := SpringCore.NewDefaultSpringContext()
+		ctx.SetProperty("app.port", 8080)
+		ctx.SetProperty("admin.port", 8081)
+
+		c := &countedConfig{}
+		ctx.RegisterConfigurationProperties("app", c)
+
+		var arg countedConfig
+		ctx.RegisterBeanFn(func(c countedConfig) *validatedServer {
+			arg = c
+			return &validatedServer{port: c.Port}
+		}, "${admin}")
+
+		countedValidations = 0
+		ctx.AutoWireBeans()
+
+		// 配置属性 Bean 和构造函数参数各自只绑定和校验一次
+		assert.Equal(t, countedValidations, 2)
+		assert.Equal(t, c.Port, 8080)
+		assert.Equal(t, arg.Port, 8081)
+	})
+
+	t.Run("config function", func(t *testing.T) {
+		ctx := SpringCore.NewDefaultSpringContext()
+		ctx.Config(func() {
+			ctx.SetProperty("admin.port", 8081)
+			ctx.SetProperty("service.port", 80)
+		})
+
+		s := &validatedService{}
+		ctx.RegisterBean(s)
+
+		var arg countedConfig
+		ctx.RegisterBeanFn(func(c countedConfig) *validatedServer {
+			arg = c
+			return &validatedServer{port: c.Port}
+		}, "${admin}")
+
+		// Config 函数设置的属性值在绑定时可见
+		ctx.AutoWireBeans()
+		assert.Equal(t, s.Port, 80)
+		assert.Equal(t, arg.Port, 8081)
+	})
+
+	t.Run("embedded pointer", func(t *testing.T) {
+		ctx := SpringCore.NewDefaultSpringContext()
+		ctx.SetProperty("service.port", 80)
+
+		s := &struct {
+			*validatedServer
+			validatedService
+		}{}
+		ctx.RegisterBean(s)
+		ctx.AutoWireBeans()
+		assert.Equal(t, s.Port, 80)
+	})
+
+	t.Run("refresh", func(t *testing.T) {
+		ctx := SpringCore.NewDefaultSpringContext()
+		ctx.SetProperty("service.port", 80)
+
+		s := &validatedService{}
+		ctx.RegisterBean(s).Refreshable()
+		ctx.AutoWireBeans()
+
+		p := SpringCore.NewDefaultProperties()
+		p.SetProperty("service.port", 8080)
+
+		_, err := ctx.RefreshProperties(p)
+		assert.Matches(t, err.Error(), `property "service.port" must be <= 1024, got 8080`)
+		assert.Equal(t, s.Port, 80)
+		assert.Equal(t, ctx.GetProperty("service.port"), 80)
+	})
+}
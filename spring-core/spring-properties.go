@@ -17,13 +17,12 @@
 package SpringCore
 
 import (
-	"errors"
 	"io"
-	"reflect"
+	"net/url"
 	"time"
 )
 
-// Properties 定义属性值接口
+// Properties 定义属性值接口，查找属性值时属性名称宽松匹配，详见 CanonicalPropertyKey。
 type Properties interface {
 	// LoadProperties 加载属性配置文件，
 	// 支持 properties、yaml 和 toml 三种文件格式。
@@ -57,49 +56,71 @@ type Properties interface {
 	// GetTimeProperty 返回 keys 中第一个存在的 Time 类型的属性值，属性名称统一转成小写。
 	GetTimeProperty(keys ...string) time.Time
 
+	// 以下 GetXxxPropertyE 方法在属性值不存在时返回 ErrPropertyNotFound 错误，见
+	// IsPropertyNotFound，属性值格式错误时返回包含属性名及其来源的 PropertyError。
+
+	// GetBoolPropertyE 返回布尔型属性值。
+	GetBoolPropertyE(key string) (bool, error)
+
+	// GetIntPropertyE 返回有符号整型属性值。
+	GetIntPropertyE(key string) (int64, error)
+
+	// GetUintPropertyE 返回无符号整型属性值。
+	GetUintPropertyE(key string) (uint64, error)
+
+	// GetFloatPropertyE 返回浮点型属性值。
+	GetFloatPropertyE(key string) (float64, error)
+
+	// GetStringPropertyE 返回字符串型属性值。
+	GetStringPropertyE(key string) (string, error)
+
+	// GetDurationPropertyE 返回 Duration 类型的属性值。
+	GetDurationPropertyE(key string) (time.Duration, error)
+
+	// GetTimePropertyE 返回 Time 类型的属性值。
+	GetTimePropertyE(key string) (time.Time, error)
+
+	// GetStringSlicePropertyE 返回字符串列表类型的属性值，字符串按照逗号切割。
+	GetStringSlicePropertyE(key string) ([]string, error)
+
+	// GetStringMapPropertyE 返回 map[string]string 类型的属性值，由以 key 为前缀的属性值组成。
+	GetStringMapPropertyE(key string) (map[string]string, error)
+
+	// GetByteSizePropertyE 返回字节数，如 64KB、1.5MB、2GiB，使用 1024 进制。
+	GetByteSizePropertyE(key string) (int64, error)
+
+	// GetURLPropertyE 返回 URL 类型的属性值，URL 必须包含 scheme。
+	GetURLPropertyE(key string) (*url.URL, error)
+
 	// GetDefaultProperty 返回属性值，如果没有找到则使用指定的默认值，属性名称统一转成小写。
 	GetDefaultProperty(key string, def interface{}) (interface{}, bool)
 
 	// SetProperty 设置属性值，属性名称统一转成小写。
 	SetProperty(key string, value interface{})
 
+	// SetPropertyWithOrigin 设置属性值并记录属性值的来源，属性名称统一转成小写。
+	SetPropertyWithOrigin(key string, value interface{}, origin PropertyOrigin)
+
+	// GetPropertyOrigin 返回属性值的来源，属性名称统一转成小写。
+	GetPropertyOrigin(key string) (PropertyOrigin, bool)
+
 	// GetPrefixProperties 返回指定前缀的属性值集合，属性名称统一转成小写。
 	GetPrefixProperties(prefix string) map[string]interface{}
 
-	// GetProperties 返回所有的属性值，属性名称统一转成小写。
+	// GetProperties 返回所有未经解析的属性值，属性名称统一转成小写。
 	GetProperties() map[string]interface{}
 
+	// GetPropertyName 返回设置属性值时使用的属性名，保留原始的大小写，找不到时
+	// 返回 key。在 Properties 之间拷贝属性值时使用它保留属性名的大小写。
+	GetPropertyName(key string) string
+
+	// GetUnusedProperties 返回从未被读取过的属性名，按照属性名排序。属性绑定、
+	// 条件判断、引用解析以及 GetProperty 等方法都会将属性标记为已读取。
+	GetUnusedProperties() []string
+
 	// BindProperty 根据类型获取属性值，属性名称统一转成小写。
 	BindProperty(key string, i interface{})
 
 	// BindPropertyIf 根据类型获取属性值，属性名称统一转成小写。
 	BindPropertyIf(key string, i interface{}, allAccess bool)
 }
-
-// typeConverters 类型转换器集合
-var typeConverters = make(map[reflect.Type]interface{})
-
-// validTypeConverter 返回是否是合法的类型转换器，类型转换器要求：
-// 必须是函数，且只能有一个字符串类型的输入参数和一个值类型的输出参数。
-func validTypeConverter(t reflect.Type) bool {
-
-	// 必须是函数 && 只能有一个输入参数 && 只能有一个输出参数
-	if t.Kind() != reflect.Func || t.NumIn() != 1 || t.NumOut() != 1 {
-		return false
-	}
-
-	inType := t.In(0)
-	outType := t.Out(0)
-
-	// 输入参数必须是字符串类型 && 输出参数必须是值类型
-	return inType.Kind() == reflect.String && IsValueType(outType.Kind())
-}
-
-// RegisterTypeConverter 注册类型转换器，函数原型 func(string)type
-func RegisterTypeConverter(fn interface{}) {
-	if t := reflect.TypeOf(fn); validTypeConverter(t) {
-		typeConverters[t.Out(0)] = fn
-	} else {
-		panic(errors.New("fn must be func(string)type"))
-	}
-}
@@ -16,6 +16,8 @@
 
 package StarterDB
 
+// DBConfig 数据库配置，使用它的 starter 将其注册为前缀为 db 的配置属性 Bean。应用
+// 自己提供数据库连接时不需要配置 db.url，因此它是可选的。
 type DBConfig struct {
-	Url string `value:"${db.url}"`
+	Url string `value:"${url:=}"` // 数据库连接地址
 }
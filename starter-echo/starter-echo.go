@@ -25,13 +25,15 @@ import (
 
 func init() {
 
-	SpringBoot.RegisterNameBeanFn("web-container", func(config WebStarter.WebServerConfig) SpringWeb.WebContainer {
+	m := SpringBoot.AutoConfiguration("echo-web-container").After("web-server")
+
+	m.RegisterNameBeanFn("web-container", func(config WebStarter.WebServerConfig) SpringWeb.WebContainer {
 		return SpringEcho.NewContainer(SpringWeb.ContainerConfig{
 			Port: config.Port,
 		})
 	}).ConditionOnOptionalPropertyValue("web.server.enable", true)
 
-	SpringBoot.RegisterNameBeanFn("ssl-web-container", func(config WebStarter.WebServerConfig) SpringWeb.WebContainer {
+	m.RegisterNameBeanFn("ssl-web-container", func(config WebStarter.WebServerConfig) SpringWeb.WebContainer {
 		return SpringEcho.NewContainer(SpringWeb.ContainerConfig{
 			EnableSSL: true,
 			Port:      config.SSLPort,
@@ -19,11 +19,15 @@ package StarterGoMongo
 import (
 	"github.com/go-spring/go-spring/spring-boot"
 	"github.com/go-spring/go-spring/starter-go-mongo/go-mongo-factory"
+	"github.com/go-spring/go-spring/starter-mongo"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func init() {
-	SpringBoot.RegisterNameBeanFn("std-go-mongo-client", GoMongoFactory.NewClient).
+	SpringBoot.AutoConfiguration("go-mongo").
+		RegisterNameBeanFn("std-go-mongo-client", GoMongoFactory.NewClient).
 		ConditionOnMissingBean((*mongo.Client)(nil)).
 		Destroy(GoMongoFactory.CloseClient)
+
+	SpringBoot.RegisterPropertyMetadata(StarterMongo.MongoConfig{})
 }
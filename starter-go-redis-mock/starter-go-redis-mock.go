@@ -23,7 +23,8 @@ import (
 )
 
 func init() {
-	SpringBoot.RegisterNameBeanFn("mock-go-redis-client", mockRedis)
+	SpringBoot.AutoConfiguration("go-redis-mock").
+		RegisterNameBeanFn("mock-go-redis-client", mockRedis)
 }
 
 // mockRedis 创建 Mock Redis
@@ -20,9 +20,13 @@ import (
 	"github.com/go-redis/redis"
 	"github.com/go-spring/go-spring/spring-boot"
 	"github.com/go-spring/go-spring/starter-go-redis/go-redis-factory"
+	"github.com/go-spring/go-spring/starter-redis"
 )
 
 func init() {
-	SpringBoot.RegisterNameBeanFn("std-go-redis-client", GoRedisFactory.NewGoRedisClient).
+	SpringBoot.AutoConfiguration("go-redis").
+		RegisterNameBeanFn("std-go-redis-client", GoRedisFactory.NewGoRedisClient).
 		ConditionOnMissingBean((*redis.Cmdable)(nil))
+
+	SpringBoot.RegisterPropertyMetadata(StarterRedis.RedisConfig{})
 }
@@ -30,7 +30,8 @@ import (
 )
 
 func init() {
-	SpringBoot.RegisterBeanFn(NewGRpcServerStarter)
+	SpringBoot.AutoConfiguration("grpc-server").RegisterBeanFn(NewGRpcServerStarter)
+	SpringBoot.RegisterPropertyMetadata(GRpcServerConfig{})
 }
 
 // GRpcServerConfig gRPC 服务器配置
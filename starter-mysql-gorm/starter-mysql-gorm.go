@@ -18,6 +18,7 @@ package StarterMySqlGorm
 
 import (
 	"database/sql"
+	"errors"
 
 	"github.com/go-spring/go-spring-parent/spring-logger"
 	"github.com/go-spring/go-spring/spring-boot"
@@ -28,19 +29,27 @@ import (
 
 func init() {
 
+	m := SpringBoot.AutoConfiguration("gorm-mysql")
+
 	// 如果没有 fromDB 名称的 *gorm.DB 对象则创建 fromConfig 名称的 *gorm.DB 对象
-	SpringBoot.RegisterNameBeanFn("std-gorm-mysql-from-config", fromConfig).
+	m.RegisterNameBeanFn("std-gorm-mysql-from-config", fromConfig).
 		ConditionOnMissingBean((*gorm.DB)(nil)).
 		Destroy(closeDB)
 
 	// 如果已经有 *sql.DB 对象则创建fromDB 名称的 *gorm.DB 对象
-	SpringBoot.RegisterNameBeanFn("std-gorm-mysql-from-db", fromDB).
+	m.RegisterNameBeanFn("std-gorm-mysql-from-db", fromDB).
 		ConditionOnBean((*sql.DB)(nil)).
 		Destroy(closeDB)
+
+	// fromConfig 使用它的值，属性值刷新时重新绑定
+	m.RegisterConfigurationProperties("db", new(StarterDB.DBConfig))
 }
 
 // fromConfig 从配置文件创建 *gorm.DB 客户端
 func fromConfig(config StarterDB.DBConfig) (*gorm.DB, error) {
+	if config.Url == "" {
+		return nil, errors.New("property db.url isn't set")
+	}
 	SpringLogger.Info("open gorm mysql", config.Url)
 	return gorm.Open("mysql", config.Url)
 }
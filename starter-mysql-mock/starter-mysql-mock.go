@@ -25,7 +25,8 @@ import (
 )
 
 func init() {
-	SpringBoot.RegisterNameBeanFn("mock-mysql-db", mockDB).Destroy(destroy)
+	SpringBoot.AutoConfiguration("mysql-mock").
+		RegisterNameBeanFn("mock-mysql-db", mockDB).Destroy(destroy)
 }
 
 // mockDB 创建 Mock DB
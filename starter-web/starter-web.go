@@ -29,23 +29,28 @@ import (
 
 func init() {
 
-	SpringBoot.RegisterNameBean("web-server", SpringWeb.NewWebServer()).
+	m := SpringBoot.AutoConfiguration("web-server")
+
+	m.RegisterNameBean("web-server", SpringWeb.NewWebServer()).
 		ConditionOnMissingBean((*SpringWeb.WebServer)(nil)).
 		ConditionOnOptionalPropertyValue("web-server.enable", true)
 
-	SpringBoot.RegisterNameBean("web-server-starter", new(WebServerStarter)).
+	m.RegisterNameBean("web-server-starter", new(WebServerStarter)).
 		ConditionOnMissingBean((*WebServerStarter)(nil)).
 		ConditionOnOptionalPropertyValue("web-server-starter.enable", true)
+
+	// Web 容器的构造函数使用它的值，属性值刷新时重新绑定
+	m.RegisterConfigurationProperties("web.server", new(WebServerConfig))
 }
 
-// WebServerConfig Web 服务器配置
+// WebServerConfig Web 服务器配置，属性名相对于 web.server
 type WebServerConfig struct {
-	EnableHTTP  bool   `value:"${web.server.enable:=true}"`      // 是否启用 HTTP
-	Port        int    `value:"${web.server.port:=8080}"`        // HTTP 端口
-	EnableHTTPS bool   `value:"${web.server.ssl.enable:=false}"` // 是否启用 HTTPS
-	SSLPort     int    `value:"${web.server.ssl.port:=8443}"`    // SSL 端口
-	SSLCert     string `value:"${web.server.ssl.cert:=}"`        // SSL 证书
-	SSLKey      string `value:"${web.server.ssl.key:=}"`         // SSL 秘钥
+	EnableHTTP  bool   `value:"${enable:=true}"`      // 是否启用 HTTP
+	Port        int    `value:"${port:=8080}"`        // HTTP 端口
+	EnableHTTPS bool   `value:"${ssl.enable:=false}"` // 是否启用 HTTPS
+	SSLPort     int    `value:"${ssl.port:=8443}"`    // SSL 端口
+	SSLCert     string `value:"${ssl.cert:=}"`        // SSL 证书
+	SSLKey      string `value:"${ssl.key:=}"`         // SSL 秘钥
 }
 
 // WebServerStarter Web 容器启动器